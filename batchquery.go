@@ -0,0 +1,111 @@
+package drysql
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// QueryIntoMapByKey runs query once for each of the keys, binding the key as the first input followed by sharedInputs,
+// and scans the rows of each run into the slice dest holds for that key, the "load the children of these parents"
+// dataloader pattern.  keys is a []K and dest a *map[K][]Child or *map[K][]*Child, fields are matched to columns by db tag.
+// The statement is prepared once and reused for every key, each key gets an entry in dest even when it matches no rows.
+
+/* 	EXAMPLE USAGE
+
+	ordersByUser := make(map[int64][]Order)
+	err = drysql.QueryIntoMapByKey("SELECT order_id, total FROM orders WHERE user_id = ? AND status = ? ORDER BY order_id DESC LIMIT 5",
+		[]int64{1, 2, 3}, []interface{}{"paid"}, &ordersByUser)
+*/
+
+func (drysql DrySql) QueryIntoMapByKey(query string, keys interface{}, sharedInputs []interface{}, dest interface{}) error {
+
+	keyValues := reflect.ValueOf(keys)
+	destValue := reflect.ValueOf(dest)
+	if keyValues.Kind() != reflect.Slice || destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Map {
+		return ErrInvalidDestination
+	}
+	results := destValue.Elem()
+	mapType := results.Type()
+	if !keyValues.Type().Elem().AssignableTo(mapType.Key()) || mapType.Elem().Kind() != reflect.Slice {
+		return ErrInvalidDestination
+	}
+	childType := mapType.Elem().Elem()
+	childIsPtr := childType.Kind() == reflect.Ptr
+	if childIsPtr {
+		childType = childType.Elem()
+	}
+	if childType.Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+	if results.IsNil() {
+		results.Set(reflect.MakeMap(mapType))
+	}
+	if keyValues.Len() == 0 {
+		return nil
+	}
+
+	probe, err := drysql.checkCircuit()
+	if err != nil {
+		return err
+	}
+	defer drysql.endProbe(probe)
+
+	start := time.Now()
+	stmtOut, err := drysql.sqlImpl.Prepare(drysql.traced(query))
+	if err != nil {
+		drysql.queryFinished(query, sharedInputs, start, err)
+		return err
+	}
+	defer stmtOut.Close()
+
+	var scanner *structScanner
+	for i := 0; i < keyValues.Len(); i++ {
+		key := keyValues.Index(i)
+		inputs := append([]interface{}{key.Interface()}, sharedInputs...)
+
+		if SqlLogger != nil {
+			SqlLogger.AddSqlRead()
+		}
+		start = time.Now()
+		rows, err := stmtOut.Query(inputs...)
+		drysql.queryFinished(query, inputs, start, err)
+		if err != nil {
+			return err
+		}
+
+		children := reflect.MakeSlice(mapType.Elem(), 0, 0)
+		for rows.Next() {
+			if scanner == nil {
+				columns, err := rows.Columns()
+				if err != nil {
+					rows.Close()
+					return err
+				}
+				scanner = drysql.newStructScanner(columns, childType)
+			}
+
+			child := reflect.New(childType)
+			if err = scanner.scan(rows, child.Elem()); err != nil {
+				rows.Close()
+				return fmt.Errorf("drysql: scanning rows for key %v: %w", key.Interface(), err)
+			}
+			if childIsPtr {
+				children = reflect.Append(children, child)
+			} else {
+				children = reflect.Append(children, child.Elem())
+			}
+		}
+		if err = rows.Err(); err != nil {
+			rows.Close()
+			return err
+		}
+		if err = rows.Close(); err != nil {
+			return err
+		}
+
+		results.SetMapIndex(key.Convert(mapType.Key()), children)
+	}
+
+	return nil
+}
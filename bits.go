@@ -0,0 +1,45 @@
+package drysql
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+)
+
+// Bits scans a MySQL BIT column, returned by the driver as big-endian bytes, into an integer and writes it back as the
+// 8 byte big-endian value MySQL accepts for any BIT(1) to BIT(64) column, e.g. `db:"flags"` on a Bits field for packed flags.
+type Bits uint64
+
+func (bits *Bits) Scan(src interface{}) error {
+
+	switch src := src.(type) {
+	case nil:
+		*bits = 0
+	case []byte:
+		if len(src) > 8 {
+			return fmt.Errorf("drysql: cannot scan %d bytes into Bits, BIT columns are at most 8 bytes", len(src))
+		}
+		var value uint64
+		for _, b := range src {
+			value = value<<8 | uint64(b)
+		}
+		*bits = Bits(value)
+	case int64:
+		*bits = Bits(src)
+	default:
+		return fmt.Errorf("drysql: cannot scan %T into Bits", src)
+	}
+
+	return nil
+}
+
+func (bits Bits) Value() (driver.Value, error) {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(bits))
+	return value, nil
+}
+
+// Has reports whether every bit set in flags is also set in bits
+func (bits Bits) Has(flags Bits) bool {
+	return bits&flags == flags
+}
@@ -0,0 +1,45 @@
+package drysql
+
+import (
+	"database/sql"
+	"io"
+)
+
+// QueryRowBlobTo writes the first column of the first row returned by query to w.
+// The column is scanned into sql.RawBytes, so it is written straight from the driver's buffer without copying it into
+// the application first.  database/sql has no streaming scan, the driver still reads the whole value off the wire.
+// Returns ErrNotFound when the query returns no rows.
+func (drysql DrySql) QueryRowBlobTo(query string, inputs []interface{}, w io.Writer) error {
+
+	stmtOut, rows, err := drysql.preparedRows(query, inputs)
+	if err != nil {
+		return err
+	}
+	defer stmtOut.Close()
+	defer rows.Close()
+
+	if err = firstRow(rows); err != nil {
+		return err
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	// RawBytes is only valid until the next call to Next, Scan or Close
+	var blob sql.RawBytes
+	targets := []interface{}{&blob}
+	for i := 1; i < len(columns); i++ {
+		targets = append(targets, new(interface{}))
+	}
+	if err = rows.Scan(targets...); err != nil {
+		return err
+	}
+
+	if _, err = w.Write(blob); err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
@@ -0,0 +1,100 @@
+package drysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("drysql: circuit open, the database is unreachable")
+
+type circuitBreaker struct {
+	failures int
+	cooldown time.Duration
+
+	mutex    sync.Mutex
+	failed   int
+	openedAt time.Time
+	probing  bool
+}
+
+// WithCircuitBreaker returns a copy of drysql that stops calling the database after failures consecutive connection
+// errors, returning ErrCircuitOpen straight away instead of piling up calls blocked on connecting.  Once cooldown has
+// passed a single call is let through as a probe, closing the circuit when it reaches the database and reopening it otherwise.
+// Any error other than a connection error, e.g. a syntax error, shows the database is reachable.
+// The circuit is shared by the returned DrySql and its copies.  A failures below 1 is taken as 1.
+func (drysql DrySql) WithCircuitBreaker(failures int, cooldown time.Duration) DrySql {
+	if failures < 1 {
+		failures = 1
+	}
+	drysql.breaker = &circuitBreaker{failures: failures, cooldown: cooldown}
+	return drysql
+}
+
+// checkCircuit returns ErrCircuitOpen when the call must not reach the database, and whether the call is the probe.
+// Callers defer endProbe with probe so the probe slot is released even on a path that records no outcome.
+func (drysql DrySql) checkCircuit() (probe bool, err error) {
+
+	breaker := drysql.breaker
+	if breaker == nil {
+		return false, nil
+	}
+
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.failed < breaker.failures {
+		return false, nil
+	}
+	if breaker.probing || time.Since(breaker.openedAt) < breaker.cooldown {
+		return false, ErrCircuitOpen
+	}
+	breaker.probing = true
+
+	return true, nil
+}
+
+// endProbe releases the probe slot taken by checkCircuit, if recordCircuit hasn't already
+func (drysql DrySql) endProbe(probe bool) {
+
+	if !probe {
+		return
+	}
+
+	breaker := drysql.breaker
+	breaker.mutex.Lock()
+	breaker.probing = false
+	breaker.mutex.Unlock()
+}
+
+// recordCircuit counts a consecutive connection failure or, for any other outcome, closes the circuit
+func (drysql DrySql) recordCircuit(err error) {
+
+	breaker := drysql.breaker
+	if breaker == nil || err == ErrCircuitOpen {
+		return
+	}
+
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	breaker.probing = false
+	if !isConnectionError(err) {
+		breaker.failed = 0
+		return
+	}
+	if breaker.failed++; breaker.failed >= breaker.failures {
+		breaker.openedAt = time.Now()
+	}
+}
+
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr)
+}
@@ -0,0 +1,93 @@
+package drysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+type breakerOrder struct {
+	OrderID int64 `db:"order_id"`
+}
+
+// openCircuit opens the circuit of db with its cooldown already passed, so the next call is the probe
+func openCircuit(db DrySql) {
+	db.breaker.failed = db.breaker.failures
+	db.breaker.openedAt = time.Now().Add(-time.Second)
+}
+
+func TestCircuitProbeReleasedWithoutOutcome(t *testing.T) {
+
+	fake, db := newFakeDB(t)
+	fake.setRows("SELECT", []string{"order_id"}, []driver.Value{int64(1)})
+	db = db.WithCircuitBreaker(1, time.Millisecond)
+
+	openCircuit(db)
+	probe, err := db.checkCircuit()
+	if err != nil || !probe {
+		t.Fatalf("checkCircuit = %v, %v, want the probe", probe, err)
+	}
+	db.endProbe(probe)
+	if probe, err = db.checkCircuit(); err != nil || !probe {
+		t.Errorf("checkCircuit after endProbe = %v, %v, want the probe", probe, err)
+	}
+	db.endProbe(probe)
+
+	openCircuit(db)
+	results := make(map[int64][]breakerOrder)
+	if err = db.QueryIntoMapByKey("SELECT order_id FROM orders WHERE user_id = ?", []int64{}, nil, &results); err != nil {
+		t.Fatal(err)
+	}
+	var orders []breakerOrder
+	if err = db.QueryIntoSlice("SELECT order_id FROM orders", nil, &orders); err != nil {
+		t.Errorf("query after QueryIntoMapByKey without keys returned %v", err)
+	}
+}
+
+func TestCircuitOpensAndCloses(t *testing.T) {
+
+	fake, appDB := newFakeDB(t)
+	fake.setRows("SELECT", []string{"order_id"}, []driver.Value{int64(1)})
+	appDB.sqlImpl.(*sql.DB).SetMaxIdleConns(0)
+	db := appDB.WithCircuitBreaker(2, 50*time.Millisecond)
+
+	var orders []breakerOrder
+	fake.failConnecting(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	for i := 0; i < 2; i++ {
+		if err := db.QueryIntoSlice("SELECT order_id FROM orders", nil, &orders); err == nil || err == ErrCircuitOpen {
+			t.Fatalf("call %d while the database is down returned %v, want the connection error", i+1, err)
+		}
+	}
+
+	// open, the database isn't called during the cooldown even once it is back
+	fake.failConnecting(nil)
+	before := len(fake.statements())
+	if err := db.QueryIntoSlice("SELECT order_id FROM orders", nil, &orders); err != ErrCircuitOpen {
+		t.Fatalf("call after 2 connection errors returned %v, want ErrCircuitOpen", err)
+	}
+	if ran := fake.statements()[before:]; len(ran) != 0 {
+		t.Errorf("open circuit ran %q", ran)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := db.QueryIntoSlice("SELECT order_id FROM orders", nil, &orders); err != nil {
+		t.Fatalf("probe after the cooldown returned %v", err)
+	}
+	if err := db.QueryIntoSlice("SELECT order_id FROM orders", nil, &orders); err != nil {
+		t.Errorf("call after a successful probe returned %v, want the circuit closed", err)
+	}
+}
+
+func TestCircuitBreakerClampsFailures(t *testing.T) {
+
+	_, db := newFakeDB(t)
+	if db = db.WithCircuitBreaker(0, time.Minute); db.breaker.failures != 1 {
+		t.Errorf("WithCircuitBreaker(0) opens after %d failures, want 1", db.breaker.failures)
+	}
+	if probe, err := db.checkCircuit(); probe || err != nil {
+		t.Errorf("checkCircuit on a closed circuit = %v, %v, want a plain call", probe, err)
+	}
+}
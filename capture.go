@@ -0,0 +1,80 @@
+package drysql
+
+import (
+	"sync"
+	"time"
+)
+
+// CapturedQuery is a query executed by a DrySql created WithQueryCapture
+type CapturedQuery struct {
+	Query    string
+	Args     []interface{}
+	Time     time.Time
+	Duration time.Duration
+	Err      error
+}
+
+type queryCapture struct {
+	mutex   sync.Mutex
+	queries []CapturedQuery
+}
+
+// WithQueryCapture returns a copy of drysql that records every query it executes, in order, for CapturedQueries.
+// Copies made from the returned DrySql, e.g. WithScope, record into the same log.
+// Intended for integration tests asserting which queries a code path issues.
+
+/* 	EXAMPLE USAGE
+
+	db := drysql.GetDrySqlImplementation(sqlDB).WithQueryCapture()
+	err = loadDashboard(db)
+	if len(db.CapturedQueries()) != 2 {
+		t.Errorf("expected 2 queries, got %d", len(db.CapturedQueries()))
+	}
+*/
+
+func (drysql DrySql) WithQueryCapture() DrySql {
+	drysql.capture = &queryCapture{}
+	return drysql
+}
+
+// CapturedQueries returns the queries recorded since WithQueryCapture or the last ResetCapturedQueries, oldest first
+func (drysql DrySql) CapturedQueries() []CapturedQuery {
+
+	if drysql.capture == nil {
+		return nil
+	}
+
+	drysql.capture.mutex.Lock()
+	defer drysql.capture.mutex.Unlock()
+
+	return append([]CapturedQuery{}, drysql.capture.queries...)
+}
+
+// ResetCapturedQueries clears the queries recorded so far
+func (drysql DrySql) ResetCapturedQueries() {
+
+	if drysql.capture == nil {
+		return
+	}
+
+	drysql.capture.mutex.Lock()
+	drysql.capture.queries = nil
+	drysql.capture.mutex.Unlock()
+}
+
+func (drysql DrySql) captureQuery(query string, inputs []interface{}, start time.Time, duration time.Duration, err error) {
+
+	if drysql.capture == nil {
+		return
+	}
+
+	drysql.capture.mutex.Lock()
+	drysql.capture.queries = append(drysql.capture.queries, CapturedQuery{
+		Query:    query,
+		Args:     append([]interface{}{}, inputs...),
+		Time:     start,
+		Duration: duration,
+		Err:      err,
+	})
+	drysql.capture.mutex.Unlock()
+}
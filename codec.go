@@ -0,0 +1,287 @@
+package drysql
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// encodeColumn applies the write side of a field's db tag options to a non-nil value about to be bound
+func (drysql DrySql) encodeColumn(tag dbTag, value driver.Value) (driver.Value, error) {
+
+	if s, ok := value.(string); ok && tag.has("enum") {
+		if err := checkEnum(tag, []byte(s)); err != nil {
+			return nil, err
+		}
+	}
+	if tag.has("encrypted") {
+		return drysql.encrypt(tag, value)
+	}
+
+	return drysql.formatColumn(tag, value), nil
+}
+
+// formatColumn applies only the format options of a field's db tag, timefmt and bool, e.g. to a value compared in a WHERE
+func (drysql DrySql) formatColumn(tag dbTag, value driver.Value) driver.Value {
+
+	if t, ok := value.(time.Time); ok && tag.has("timefmt") {
+		return t.Format(tag.get("timefmt"))
+	}
+	if b, ok := value.(bool); ok {
+		if trueValue, falseValue, ok := drysql.boolEncoding(tag); ok {
+			if b {
+				return trueValue
+			}
+			return falseValue
+		}
+	}
+
+	return value
+}
+
+// decodeFunc converts the driver value of a column into a struct field
+type decodeFunc func(src interface{}, field reflect.Value) error
+
+// fieldDecoder returns the read side of a field's db tag options, or nil when the column scans straight into the field
+func (drysql DrySql) fieldDecoder(field reflect.StructField, tag dbTag) decodeFunc {
+
+	if trueValue, falseValue, ok := drysql.boolEncoding(tag); ok && isBoolType(field.Type) {
+		return boolDecoder(trueValue, falseValue)
+	}
+	if tag.has("timefmt") && isTimeType(field.Type) {
+		return timeDecoder(tag.get("timefmt"))
+	}
+	if tag.has("jsonagg") {
+		return drysql.jsonAggDecoder
+	}
+
+	// transforms applied in order to the bytes of a string or []byte field
+	var transforms []func([]byte) ([]byte, error)
+	if tag.has("encrypted") {
+		transforms = append(transforms, drysql.decrypt)
+	}
+	if tag.has("trim") || (drysql.trimStrings && isStringType(field.Type)) {
+		transforms = append(transforms, trimPadding)
+	}
+	if tag.has("enum") {
+		transforms = append(transforms, func(data []byte) ([]byte, error) {
+			return data, checkEnum(tag, data)
+		})
+	}
+
+	if len(transforms) == 0 {
+		return nil
+	}
+
+	return func(src interface{}, dest reflect.Value) error {
+		if src == nil {
+			return setBytes(dest, nil)
+		}
+
+		data, ok := srcBytes(src)
+		if !ok {
+			// e.g. an int64 or time.Time from a prepared statement, converted as database/sql would scan it into a string
+			var converted sql.NullString
+			if err := converted.Scan(src); err != nil {
+				return fmt.Errorf("drysql: cannot decode %T into %s: %w", src, dest.Type(), err)
+			}
+			data = []byte(converted.String)
+		}
+
+		var err error
+		for _, transform := range transforms {
+			if data, err = transform(data); err != nil {
+				return err
+			}
+		}
+
+		return setBytes(dest, data)
+	}
+}
+
+// WithTrimStrings returns a copy of drysql that trims trailing spaces from every string or *string field it scans,
+// as if each was tagged `db:"column_name,trim"`.  Use it for legacy schemas full of space padded CHAR columns.
+func (drysql DrySql) WithTrimStrings() DrySql {
+	drysql.trimStrings = true
+	return drysql
+}
+
+func trimPadding(data []byte) ([]byte, error) {
+	return bytes.TrimRight(data, " "), nil
+}
+
+// checkEnum returns an error unless value is one of the | separated values of the enum option, e.g. `db:"status,enum=active|inactive"`
+func checkEnum(tag dbTag, value []byte) error {
+	for _, allowed := range strings.Split(tag.get("enum"), "|") {
+		if string(value) == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("drysql: %q is not one of the %s values %s", value, tag.name, tag.get("enum"))
+}
+
+// WithBoolEncoding returns a copy of drysql that writes bool fields as trueValue and falseValue and reads them back,
+// as if each was tagged `db:"column_name,bool=Y/N"`.  Use it for legacy schemas storing booleans in CHAR columns,
+// a bool tag option on a field takes precedence.
+func (drysql DrySql) WithBoolEncoding(trueValue string, falseValue string) DrySql {
+	drysql.boolTrue, drysql.boolFalse = trueValue, falseValue
+	return drysql
+}
+
+// boolEncoding returns the representations of true and false for a field, from its bool=true/false tag option or WithBoolEncoding
+func (drysql DrySql) boolEncoding(tag dbTag) (trueValue string, falseValue string, ok bool) {
+
+	if encoding := tag.get("bool"); encoding != "" {
+		if i := strings.Index(encoding, "/"); i >= 0 {
+			return encoding[:i], encoding[i+1:], true
+		}
+	}
+	if drysql.boolTrue != "" || drysql.boolFalse != "" {
+		return drysql.boolTrue, drysql.boolFalse, true
+	}
+
+	return "", "", false
+}
+
+// boolDecoder scans a bool or *bool field from its encoded representation, still accepting native booleans and integers
+func boolDecoder(trueValue string, falseValue string) decodeFunc {
+	return func(src interface{}, dest reflect.Value) error {
+
+		var b bool
+		switch src := src.(type) {
+		case nil:
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		case bool:
+			b = src
+		case int64:
+			b = src != 0
+		default:
+			data, ok := srcBytes(src)
+			if !ok {
+				return fmt.Errorf("drysql: cannot decode %T into %s", src, dest.Type())
+			}
+			switch value := strings.TrimSpace(string(data)); {
+			case strings.EqualFold(value, trueValue):
+				b = true
+			case strings.EqualFold(value, falseValue):
+				b = false
+			default:
+				return fmt.Errorf("drysql: cannot decode %q into %s, expected %q or %q", value, dest.Type(), trueValue, falseValue)
+			}
+		}
+
+		if dest.Kind() == reflect.Ptr {
+			dest.Set(reflect.New(dest.Type().Elem()))
+			dest = dest.Elem()
+		}
+		dest.SetBool(b)
+		return nil
+	}
+}
+
+// timeDecoder parses a time.Time or *time.Time field from a string column formatted with layout, as UTC,
+// e.g. `db:"effective_date,timefmt=20060102"` for a CHAR(8) date.  The write helpers format the field with the same layout.
+func timeDecoder(layout string) decodeFunc {
+	return func(src interface{}, dest reflect.Value) error {
+
+		var t time.Time
+		switch src := src.(type) {
+		case nil:
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		case time.Time:
+			t = src
+		default:
+			data, ok := srcBytes(src)
+			if !ok {
+				return fmt.Errorf("drysql: cannot decode %T into %s", src, dest.Type())
+			}
+			var err error
+			if t, err = time.Parse(layout, strings.TrimSpace(string(data))); err != nil {
+				return err
+			}
+		}
+
+		if dest.Kind() == reflect.Ptr {
+			dest.Set(reflect.New(dest.Type().Elem()))
+			dest = dest.Elem()
+		}
+		dest.Set(reflect.ValueOf(t))
+		return nil
+	}
+}
+
+func isTimeType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == timeType
+}
+
+func isBoolType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
+
+func isStringType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
+
+// decodingScanner is handed to rows.Scan in place of a field that needs decoding
+type decodingScanner struct {
+	field  reflect.Value
+	decode decodeFunc
+}
+
+func (scanner decodingScanner) Scan(src interface{}) error {
+	return scanner.decode(src, scanner.field)
+}
+
+// setBytes assigns data to a string or []byte field, or a pointer to one, setting nil data as the zero value
+func setBytes(field reflect.Value, data []byte) error {
+
+	if field.Kind() == reflect.Ptr {
+		if data == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		ptr := reflect.New(field.Type().Elem())
+		if err := setBytes(ptr.Elem(), data); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(string(data))
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8:
+		field.SetBytes(data)
+	default:
+		return fmt.Errorf("drysql: cannot decode into field of type %s, must be a string or []byte", field.Type())
+	}
+
+	return nil
+}
+
+// srcBytes returns a string or []byte driver value as bytes
+func srcBytes(src interface{}) ([]byte, bool) {
+	switch src := src.(type) {
+	case []byte:
+		return src, true
+	case string:
+		return []byte(src), true
+	}
+	return nil, false
+}
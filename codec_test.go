@@ -0,0 +1,30 @@
+package drysql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrimStringsConvertsOtherValues(t *testing.T) {
+
+	type row struct {
+		Code      string  `db:"code"`
+		Count     string  `db:"count"`
+		CreatedAt *string `db:"created_at"`
+	}
+
+	fake, db := newFakeDB(t)
+	fake.setRows("SELECT", []string{"code", "count", "created_at"},
+		[]driver.Value{[]byte("AB  "), int64(42), time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)})
+
+	var rows []row
+	if err := db.WithTrimStrings().QueryIntoSlice("SELECT code, count, created_at FROM codes", nil, &rows); err != nil {
+		t.Fatal(err)
+	}
+	createdAt := "2024-03-01T12:00:00Z"
+	if want := []row{{Code: "AB", Count: "42", CreatedAt: &createdAt}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %+v, want %+v", rows, want)
+	}
+}
@@ -0,0 +1,51 @@
+package drysql
+
+import (
+	"reflect"
+	"strings"
+)
+
+// indirectType returns the struct type of a struct, pointer to struct or slice of either
+func indirectType(structType interface{}) reflect.Type {
+	t := reflect.TypeOf(structType)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	return t
+}
+
+// taggedColumns returns the db tags of a struct type in field order, leaving out jsonagg fields which are built by the query
+func taggedColumns(t reflect.Type) []string {
+	var columns []string
+	if t == nil || t.Kind() != reflect.Struct {
+		return columns
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if tag := parseTag(t.Field(i)); tag.name != "" && !tag.has("jsonagg") {
+			columns = append(columns, tag.name)
+		}
+	}
+	return columns
+}
+
+// SelectColumns returns the comma separated db tags of structType, each prefixed with "prefix." when prefix is not empty
+// and quoted when created WithQuotedIdentifiers.
+// Use it to keep a hand written SELECT in sync with the struct it is scanned into, adding the json_agg or JSON_ARRAYAGG
+// expression of any jsonagg field yourself.
+
+/* 	EXAMPLE USAGE
+
+	query := "SELECT " + drysql.SelectColumns(User{}, "u") + " FROM my_users u JOIN my_teams t ON t.team_id = u.team_id"
+*/
+
+func (drysql DrySql) SelectColumns(structType interface{}, prefix string) string {
+
+	columns := drysql.quoteAll(taggedColumns(indirectType(structType)))
+	if len(prefix) > 0 {
+		for i := range columns {
+			columns[i] = prefix + "." + columns[i]
+		}
+	}
+
+	return strings.Join(columns, ", ")
+}
@@ -0,0 +1,133 @@
+package drysql
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrNoConditions = errors.New("drysql: refusing to run without a condition")
+
+var ErrTruncateNotConfirmed = errors.New("drysql: refusing to truncate without confirmation")
+
+var ErrTruncateScoped = errors.New("drysql: refusing to truncate with a scope, use DeleteTableRows")
+
+var ErrUpsertScoped = errors.New("drysql: refusing to upsert with a scope, the conflicting row may be outside it")
+
+// Condition is a parameterized SQL boolean expression, e.g. drysql.Where("tenant_id = ? AND deleted_at IS NULL", tenantID).
+// The helpers wrap each condition in parentheses and AND them together with any scope added WithScope.
+// Always use ? placeholders in a condition, generated queries are rewritten for the dialect before they are run.
+type Condition struct {
+	Clause string
+	Args   []interface{}
+}
+
+func Where(clause string, args ...interface{}) Condition {
+	return Condition{Clause: clause, Args: args}
+}
+
+// conditionClause ANDs the scope of drysql with conditions, skipping empty clauses
+func (drysql DrySql) conditionClause(conditions []Condition) (string, []interface{}) {
+
+	var clauses []string
+	var args []interface{}
+	for _, condition := range append(append([]Condition{}, drysql.scope...), conditions...) {
+		if len(condition.Clause) > 0 {
+			clauses = append(clauses, "("+condition.Clause+")")
+			args = append(args, condition.Args...)
+		}
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+func (drysql DrySql) whereClause(conditions []Condition) (string, []interface{}) {
+
+	clause, args := drysql.conditionClause(conditions)
+	if len(clause) == 0 {
+		return "", nil
+	}
+
+	return " WHERE " + clause, args
+}
+
+// SelectTableRows selects the db tagged columns of dest's struct type from tableName into dest, see QueryIntoSlice.
+// An empty tableName is derived from the struct type, see Tabler and WithTableNamer.
+
+/* 	EXAMPLE USAGE
+
+	var users []User
+	err = drysql.SelectTableRows("my_users", &users, drysql.Where("last_name = ?", "Smith"))
+*/
+
+func (drysql DrySql) SelectTableRows(tableName string, dest interface{}, conditions ...Condition) error {
+
+	return drysql.SelectTableRowsOrderBy(tableName, dest, nil, conditions...)
+}
+
+// CountTableRows returns the number of rows in tableName matching conditions
+func (drysql DrySql) CountTableRows(tableName string, conditions ...Condition) (count int64, err error) {
+
+	drysql.warnOnTypeMismatch(tableName, conditions)
+	where, inputs := drysql.whereClause(conditions)
+	err = drysql.QueryRow(drysql.rebind("SELECT COUNT(*) FROM "+drysql.quote(tableName)+where), inputs, []interface{}{&count})
+
+	return count, err
+}
+
+// DeleteTableRows deletes the rows in tableName matching conditions and returns the number deleted.
+// Returns ErrNoConditions rather than deleting every row when there are no conditions or scope.
+func (drysql DrySql) DeleteTableRows(tableName string, conditions ...Condition) (int64, error) {
+
+	drysql.warnOnTypeMismatch(tableName, conditions)
+	where, inputs := drysql.whereClause(conditions)
+	if len(where) == 0 {
+		return 0, ErrNoConditions
+	}
+
+	result, err := drysql.PreparedExec(drysql.rebind("DELETE FROM "+drysql.quote(tableName)+where), inputs)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// TruncateOption adds a Postgres option to TruncateTable, other dialects ignore them
+type TruncateOption string
+
+const (
+	RestartIdentity TruncateOption = "RESTART IDENTITY" // reset the sequences owned by the table's columns
+	Cascade         TruncateOption = "CASCADE"          // also truncate the tables with foreign keys referencing it
+)
+
+// TruncateTable removes every row of tableName with TRUNCATE TABLE, e.g. for test teardown.  As it can't be undone it only
+// runs when confirm is true, returning ErrTruncateNotConfirmed otherwise, and it refuses to run with a scope added WithScope
+// as TRUNCATE would ignore it.  SQLite has no TRUNCATE, every row is deleted instead.
+
+/* 	EXAMPLE USAGE
+
+	err = drysql.WithDialect(drysql.Postgres).TruncateTable("my_users", true, drysql.RestartIdentity, drysql.Cascade)
+*/
+
+func (drysql DrySql) TruncateTable(tableName string, confirm bool, options ...TruncateOption) error {
+
+	if !confirm {
+		return ErrTruncateNotConfirmed
+	}
+	if len(drysql.scope) > 0 {
+		return ErrTruncateScoped
+	}
+
+	query := "TRUNCATE TABLE " + drysql.quote(tableName)
+	switch drysql.dialect {
+	case SQLite:
+		query = "DELETE FROM " + drysql.quote(tableName)
+	case Postgres:
+		for _, option := range options {
+			query += " " + string(option)
+		}
+	}
+
+	_, err := drysql.PreparedExec(query, nil)
+	return err
+}
@@ -0,0 +1,108 @@
+package drysql
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"io"
+)
+
+// csvReader produces the CSV of a result set as it is read, one row at a time
+type csvReader struct {
+	stmt    *sql.Stmt
+	rows    *sql.Rows
+	buffer  bytes.Buffer
+	writer  *csv.Writer
+	values  []sql.RawBytes
+	targets []interface{}
+	done    bool
+}
+
+// QueryCSVReader runs a prepared query and returns a reader producing its result as CSV, a header row of column names
+// followed by one record per row, with NULL as an empty field.  Rows are only read from the database as the CSV is read,
+// so a slow consumer such as an HTTP client applies backpressure without buffering the whole result.
+// The caller must Close the reader, which releases the statement and its connection.
+
+/* 	EXAMPLE USAGE
+
+	func export(w http.ResponseWriter, r *http.Request) {
+		report, err := db.QueryCSVReader("SELECT user_id, first_name, last_name FROM my_users", nil)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer report.Close()
+
+		w.Header().Set("Content-Type", "text/csv")
+		io.Copy(w, report)
+	}
+*/
+
+func (drysql DrySql) QueryCSVReader(query string, inputs []interface{}) (io.ReadCloser, error) {
+
+	stmtOut, rows, err := drysql.preparedRows(query, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		stmtOut.Close()
+		return nil, err
+	}
+
+	reader := &csvReader{stmt: stmtOut, rows: rows, values: make([]sql.RawBytes, len(columns)), targets: make([]interface{}, len(columns))}
+	for i := range reader.values {
+		reader.targets[i] = &reader.values[i]
+	}
+	reader.writer = csv.NewWriter(&reader.buffer)
+	if err = reader.writer.Write(columns); err != nil {
+		reader.Close()
+		return nil, err
+	}
+	reader.writer.Flush()
+
+	return reader, nil
+}
+
+func (reader *csvReader) Read(p []byte) (int, error) {
+
+	for reader.buffer.Len() == 0 && !reader.done {
+		if !reader.rows.Next() {
+			reader.done = true
+			if err := reader.rows.Err(); err != nil {
+				return 0, err
+			}
+			break
+		}
+
+		if err := reader.rows.Scan(reader.targets...); err != nil {
+			return 0, err
+		}
+		record := make([]string, len(reader.values))
+		for i, value := range reader.values {
+			record[i] = string(value)
+		}
+		if err := reader.writer.Write(record); err != nil {
+			return 0, err
+		}
+		reader.writer.Flush()
+	}
+
+	if reader.buffer.Len() == 0 {
+		return 0, io.EOF
+	}
+	return reader.buffer.Read(p)
+}
+
+// Close closes the rows and the prepared statement, returning the first error
+func (reader *csvReader) Close() error {
+
+	err := reader.rows.Close()
+	if stmtErr := reader.stmt.Close(); err == nil {
+		err = stmtErr
+	}
+
+	return err
+}
@@ -0,0 +1,88 @@
+package drysql
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// Cursor iterates the rows of a query like sql.Rows, adding ScanStruct with the same field mapping as QueryIntoSlice.
+// It holds a prepared statement and its connection until Close is called.
+type Cursor struct {
+	drysql     DrySql
+	stmt       *sql.Stmt
+	rows       *sql.Rows
+	scanner    *structScanner
+	structType reflect.Type
+}
+
+// PreparedQueryCursor runs a prepared query and returns a Cursor over its rows, the caller must Close it
+
+/* 	EXAMPLE USAGE
+
+	cursor, err := drysql.PreparedQueryCursor("SELECT user_id, first_name FROM my_users", nil)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	for cursor.Next() {
+		var user User
+		if err = cursor.ScanStruct(&user); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+*/
+
+func (drysql DrySql) PreparedQueryCursor(query string, inputs []interface{}) (*Cursor, error) {
+
+	stmtOut, rows, err := drysql.preparedRows(query, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cursor{drysql: drysql, stmt: stmtOut, rows: rows}, nil
+}
+
+func (cursor *Cursor) Next() bool {
+	return cursor.rows.Next()
+}
+
+func (cursor *Cursor) Scan(dest ...interface{}) error {
+	return cursor.rows.Scan(dest...)
+}
+
+// ScanStruct scans the current row into the struct dest points to, matching columns to fields by db tag
+func (cursor *Cursor) ScanStruct(dest interface{}) error {
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	if cursor.scanner == nil || cursor.structType != v.Elem().Type() {
+		columns, err := cursor.rows.Columns()
+		if err != nil {
+			return err
+		}
+		cursor.structType = v.Elem().Type()
+		cursor.scanner = cursor.drysql.newStructScanner(columns, cursor.structType)
+	}
+
+	return cursor.scanner.scan(cursor.rows, v.Elem())
+}
+
+func (cursor *Cursor) Err() error {
+	return cursor.rows.Err()
+}
+
+// Close closes the rows and the prepared statement, returning the first error
+func (cursor *Cursor) Close() error {
+
+	err := cursor.rows.Close()
+	if stmtErr := cursor.stmt.Close(); err == nil {
+		err = stmtErr
+	}
+
+	return err
+}
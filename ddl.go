@@ -0,0 +1,182 @@
+package drysql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	bytesType       = reflect.TypeOf([]byte(nil))
+	nullStringType  = reflect.TypeOf(sql.NullString{})
+	nullInt64Type   = reflect.TypeOf(sql.NullInt64{})
+	nullInt32Type   = reflect.TypeOf(sql.NullInt32{})
+	nullFloat64Type = reflect.TypeOf(sql.NullFloat64{})
+	nullBoolType    = reflect.TypeOf(sql.NullBool{})
+	nullTimeType    = reflect.TypeOf(sql.NullTime{})
+)
+
+// columnType infers the SQL type of a Go field type for the dialect, returning "" when it has no mapping
+func (dialect Dialect) columnType(t reflect.Type) string {
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType, nullTimeType:
+		switch dialect {
+		case Postgres:
+			return "TIMESTAMP"
+		default:
+			return "DATETIME"
+		}
+	case bytesType:
+		switch dialect {
+		case Postgres:
+			return "BYTEA"
+		default:
+			return "BLOB"
+		}
+	case nullStringType:
+		t = reflect.TypeOf("")
+	case nullInt64Type:
+		t = reflect.TypeOf(int64(0))
+	case nullInt32Type:
+		t = reflect.TypeOf(int32(0))
+	case nullFloat64Type:
+		t = reflect.TypeOf(float64(0))
+	case nullBoolType:
+		t = reflect.TypeOf(false)
+	}
+
+	if dialect == SQLite {
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Bool:
+			return "INTEGER"
+		case reflect.Float32, reflect.Float64:
+			return "REAL"
+		case reflect.String:
+			return "TEXT"
+		}
+		return ""
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "SMALLINT"
+	case reflect.Int32, reflect.Uint16:
+		if dialect == Postgres {
+			return "INTEGER"
+		}
+		return "INT"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return "BIGINT"
+	case reflect.Float32:
+		if dialect == Postgres {
+			return "REAL"
+		}
+		return "FLOAT"
+	case reflect.Float64:
+		if dialect == Postgres {
+			return "DOUBLE PRECISION"
+		}
+		return "DOUBLE"
+	case reflect.String:
+		if dialect == Postgres {
+			return "TEXT"
+		}
+		return "VARCHAR(255)"
+	}
+
+	return ""
+}
+
+// CreateTableSQL returns a CREATE TABLE IF NOT EXISTS statement with a column for each db tagged field of structType.
+// Column types are inferred from the field types for the dialect, override one with a type option, e.g. `db:"name,type=VARCHAR(100)"`.
+// Constraints are added with the notnull, unique, pk, default=<expression> and comment=<text> options,
+// e.g. `db:"email,unique,notnull"`.  Several pk fields form a composite primary key, comments are only emitted for MySQL.
+// Intended for tests and development bootstrapping, not as a replacement for migrations.
+func (drysql DrySql) CreateTableSQL(tableName string, structType interface{}) (string, error) {
+
+	t := indirectType(structType)
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", ErrInvalidDestination
+	}
+
+	var columns, primaryKey []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := parseTag(field)
+		if tag.name == "" || tag.has("jsonagg") {
+			continue
+		}
+
+		sqlType := tag.get("type")
+		if sqlType == "" && tag.has("timefmt") {
+			// stored as formatted text
+			sqlType = drysql.dialect.columnType(reflect.TypeOf(""))
+		}
+		if sqlType == "" {
+			if sqlType = drysql.dialect.columnType(field.Type); sqlType == "" {
+				return "", fmt.Errorf("drysql: no column type for field %s of type %s, add a type option to its db tag", field.Name, field.Type)
+			}
+		}
+
+		column := drysql.quote(tag.name) + " " + sqlType
+		if tag.has("notnull") {
+			column += " NOT NULL"
+		}
+		if tag.has("unique") {
+			column += " UNIQUE"
+		}
+		if tag.has("default") {
+			column += " DEFAULT " + tag.get("default")
+		}
+		if tag.has("comment") && drysql.dialect == MySQL {
+			column += " COMMENT '" + strings.Replace(tag.get("comment"), "'", "''", -1) + "'"
+		}
+		if tag.has("pk") {
+			primaryKey = append(primaryKey, drysql.quote(tag.name))
+		}
+		columns = append(columns, column)
+	}
+
+	if len(primaryKey) > 0 {
+		columns = append(columns, "PRIMARY KEY ("+strings.Join(primaryKey, ", ")+")")
+	}
+
+	return "CREATE TABLE IF NOT EXISTS " + drysql.quote(drysql.tableName(tableName, structType)) + " (" + strings.Join(columns, ", ") + ")", nil
+}
+
+// CreateTableFromStruct creates tableName from the db tagged fields of structType if it does not already exist, see CreateTableSQL
+
+/* 	EXAMPLE USAGE
+
+	type User struct {
+		UserID    int64     `db:"user_id,pk"`
+		Email     string    `db:"email,unique,notnull"`
+		FirstName string    `db:"first_name,type=VARCHAR(100)"`
+		CreatedAt time.Time `db:"created_at,notnull,default=CURRENT_TIMESTAMP"`
+	}
+
+	err = drysql.WithDialect(drysql.SQLite).CreateTableFromStruct("my_users", User{})
+*/
+
+func (drysql DrySql) CreateTableFromStruct(tableName string, structType interface{}) error {
+
+	query, err := drysql.CreateTableSQL(tableName, structType)
+	if err != nil {
+		return err
+	}
+
+	_, err = drysql.PreparedExec(query, nil)
+
+	return err
+}
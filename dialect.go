@@ -0,0 +1,56 @@
+package drysql
+
+import (
+	"strings"
+)
+
+// Dialect selects the SQL syntax drysql generates
+type Dialect int
+
+const (
+	MySQL Dialect = iota
+	Postgres
+	SQLite
+)
+
+// WithDialect returns a copy of drysql that generates SQL for dialect, the default is MySQL
+func (drysql DrySql) WithDialect(dialect Dialect) DrySql {
+	drysql.dialect = dialect
+	return drysql
+}
+
+// WithQuotedIdentifiers returns a copy of drysql that quotes the table and column names in the SQL it generates,
+// with backticks for MySQL and double quotes otherwise, so they keep the exact case of the db tag.
+// By default identifiers are left unquoted and are folded by the database, Postgres folds `db:"UserID"` to userid.
+func (drysql DrySql) WithQuotedIdentifiers() DrySql {
+	drysql.quoteIdentifiers = true
+	return drysql
+}
+
+// quote quotes each part of a possibly schema qualified identifier when quoting is enabled
+func (drysql DrySql) quote(identifier string) string {
+
+	if !drysql.quoteIdentifiers {
+		return identifier
+	}
+
+	quote := `"`
+	if drysql.dialect == MySQL {
+		quote = "`"
+	}
+
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		parts[i] = quote + strings.Replace(part, quote, quote+quote, -1) + quote
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func (drysql DrySql) quoteAll(identifiers []string) []string {
+	quoted := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		quoted[i] = drysql.quote(identifier)
+	}
+	return quoted
+}
@@ -0,0 +1,134 @@
+package drysql
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ColumnChange is a column written by UpdateTableRowFromDiff with its value before and after the change,
+// as driver values so pointers are dereferenced and nil is NULL
+type ColumnChange struct {
+	Column   string
+	OldValue interface{}
+	NewValue interface{}
+}
+
+// UpdateTableRowFromDiff updates only the db tagged columns whose value differs between oldStruct and newStruct,
+// two values of the same struct type, and returns what changed, e.g. as the payload of an audit event.
+// The row is identified by the rowIdentifierTag value of newStruct and additionally matched by conditions and any scope.
+// readonly fields are never compared or written.  When nothing changed no query is run and no changes are returned,
+// with ErrNoUpdatableFields if created WithStrictUpdates.
+
+/* 	EXAMPLE USAGE
+
+	before := user
+	user.FirstName = "Ann"
+	changes, err := drysql.UpdateTableRowFromDiff("my_users", "user_id", before, user)
+	if err == nil && len(changes) > 0 {
+		emitAuditEvent(user.UserID, changes)
+	}
+*/
+
+func (drysql DrySql) UpdateTableRowFromDiff(tableName string, rowIdentifierTag string, oldStruct interface{}, newStruct interface{}, conditions ...Condition) ([]ColumnChange, error) {
+
+	oldValue := reflect.Indirect(reflect.ValueOf(oldStruct))
+	newValue := reflect.Indirect(reflect.ValueOf(newStruct))
+	if oldValue.Kind() != reflect.Struct || newValue.Kind() != reflect.Struct || oldValue.Type() != newValue.Type() {
+		return nil, ErrInvalidDestination
+	}
+	t := newValue.Type()
+
+	if err := validate(newStruct); err != nil {
+		return nil, err
+	}
+
+	keyIndex, ok := taggedField(t, rowIdentifierTag)
+	if !ok {
+		return nil, fmt.Errorf("drysql: %s has no field tagged %q", t, rowIdentifierTag)
+	}
+
+	var changes []ColumnChange
+	var columnsToUpdate []string
+	var inputs []interface{}
+	for i := 0; i < t.NumField(); i++ {
+		tag := parseTag(t.Field(i))
+		if tag.name == "" || tag.has("readonly") || tag.has("jsonagg") || i == keyIndex {
+			continue
+		}
+
+		before, err := diffValue(oldValue.Field(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		after, err := diffValue(newValue.Field(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if driverValuesEqual(before, after) {
+			continue
+		}
+
+		changes = append(changes, ColumnChange{Column: tag.name, OldValue: before, NewValue: after})
+		if after != nil {
+			if after, err = drysql.encodeColumn(tag, after); err != nil {
+				return nil, err
+			}
+		}
+		columnsToUpdate = append(columnsToUpdate, drysql.quote(tag.name)+" = ?")
+		inputs = append(inputs, after)
+	}
+
+	if len(changes) == 0 {
+		if drysql.strictUpdates {
+			return nil, ErrNoUpdatableFields
+		}
+		return nil, nil
+	}
+
+	key, err := driver.DefaultParameterConverter.ConvertValue(newValue.Field(keyIndex).Interface())
+	if err != nil {
+		return nil, err
+	}
+	inputs = append(inputs, key)
+
+	var conditional string
+	if clause, args := drysql.conditionClause(conditions); len(clause) > 0 {
+		conditional = " AND " + clause
+		inputs = append(inputs, args...)
+	}
+
+	tableName = drysql.tableName(tableName, newStruct)
+	drysql.warnOnTypeMismatch(tableName, append([]Condition{Where(rowIdentifierTag+" = ?", key)}, conditions...))
+	query := "UPDATE " + drysql.quote(tableName) + " SET " + strings.Join(columnsToUpdate, ", ") +
+		" WHERE " + drysql.quote(rowIdentifierTag) + " = ?" + conditional
+	if _, err = drysql.PreparedExec(drysql.rebind(query), inputs); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// diffValue converts a field to the driver value it is compared and written as, Null is NULL like in the other write helpers
+func diffValue(fieldValue interface{}) (driver.Value, error) {
+	if fieldValue == Null {
+		return nil, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(fieldValue)
+}
+
+// driverValuesEqual compares two driver values, times by instant rather than by location
+func driverValuesEqual(a interface{}, b interface{}) bool {
+	switch a := a.(type) {
+	case time.Time:
+		b, ok := b.(time.Time)
+		return ok && a.Equal(b)
+	case []byte:
+		b, ok := b.([]byte)
+		return ok && bytes.Equal(a, b) && (a == nil) == (b == nil)
+	}
+	return a == b
+}
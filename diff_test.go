@@ -0,0 +1,38 @@
+package drysql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUpdateTableRowFromDiff(t *testing.T) {
+
+	type user struct {
+		UserID    int64       `db:"user_id"`
+		FirstName string      `db:"first_name"`
+		LastName  string      `db:"last_name"`
+		DeletedAt interface{} `db:"deleted_at"`
+	}
+
+	fake, db := newFakeDB(t)
+	before := user{UserID: 1, FirstName: "Ann", LastName: "Lee", DeletedAt: "2024-03-01"}
+	after := user{UserID: 1, FirstName: "Bo", LastName: "Lee", DeletedAt: Null}
+
+	changes, err := db.UpdateTableRowFromDiff("my_users", "user_id", before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantChanges := []ColumnChange{{Column: "first_name", OldValue: "Ann", NewValue: "Bo"}, {Column: "deleted_at", OldValue: "2024-03-01", NewValue: nil}}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("got %+v, want %+v", changes, wantChanges)
+	}
+	want := []string{"UPDATE my_users SET first_name = ?, deleted_at = ? WHERE user_id = ? [Bo <nil> 1]"}
+	if got := fake.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+
+	// Null is NULL on both sides, so setting it again changes nothing
+	if changes, err = db.UpdateTableRowFromDiff("my_users", "user_id", after, after); err != nil || changes != nil {
+		t.Errorf("unchanged diff = %+v, %v", changes, err)
+	}
+}
@@ -1,10 +1,13 @@
 package drysql
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"reflect"
 	"strings"
+	"time"
 )
 
 type SqlInterface interface {
@@ -14,13 +17,63 @@ type SqlInterface interface {
 }
 
 type DrySql struct {
-	sqlImpl SqlInterface
+	sqlImpl              SqlInterface
+	scope                []Condition
+	fullScanWarnings     bool
+	fullScanMinRows      int64
+	strictUpdates        bool
+	explainWrites        bool
+	normalizeColumn      func(string) string
+	dialect              Dialect
+	encryptor            FieldEncryptor
+	unknownKeyErrors     bool
+	maxRows              int
+	trimStrings          bool
+	quoteIdentifiers     bool
+	tableNamer           func(structName string) string
+	continueOnScanErrors bool
+	boolTrue             string
+	boolFalse            string
+	upsertVersionColumn  string
+	placeholder          PlaceholderFunc
+	traceComment         string
+	decoderID            uint64 // identifies the normalizeColumn and encryptor funcs, which can't be compared, for the request cache
+
+	slowQueryThreshold time.Duration
+	slowQueryLogger    SlowQueryLoggingInterface
+	slowQueryArgs      bool
+	capture            *queryCapture
+	repeatedQueries    *repeatedQueryDetector
+	breaker            *circuitBreaker
+	columnTypes        *columnTypeCache
+	metrics            *MetricsRegistry
+	requestCache       *requestCache
 }
 
 func GetDrySqlImplementation(sqlImpl SqlInterface) DrySql {
 	return DrySql{sqlImpl: sqlImpl}
 }
 
+// WithScope returns a copy of drysql that ANDs clause into the WHERE of every query it generates,
+// e.g. drysql.WithScope("tenant_id = ?", tenantID).  Repeated calls are combined with AND.
+// Raw queries passed to PreparedQuery, QueryRow, QueryIntoSlice etc. are not modified.  Helpers that can't apply the
+// scope refuse to run instead, TruncateTable with ErrTruncateScoped, BatchUpsertFromStructs and InsertFromStructIdempotent
+// with ErrUpsertScoped.
+func (drysql DrySql) WithScope(clause string, args ...interface{}) DrySql {
+
+	// copy rather than append in place so sibling scopes never share a backing array
+	drysql.scope = append(append([]Condition{}, drysql.scope...), Where(clause, args...))
+
+	return drysql
+}
+
+// WithStrictUpdates returns a copy of drysql whose UpdateTableRowFromStruct returns ErrNoUpdatableFields
+// instead of silently succeeding when the struct has no non-nil fields to update
+func (drysql DrySql) WithStrictUpdates() DrySql {
+	drysql.strictUpdates = true
+	return drysql
+}
+
 type SqlLoggingInterface interface {
 	AddSqlRead()
 	AddSqlWrite()
@@ -28,10 +81,69 @@ type SqlLoggingInterface interface {
 
 var SqlLogger SqlLoggingInterface
 
+var ErrNoUpdatableFields = errors.New("drysql: no updatable fields")
+
+// ErrNotFound is returned by the single row helpers, QueryRow, QueryRowInt and the like, QueryScalar and QueryRowBlobTo,
+// when the query returns no rows.  ErrNotFound wraps sql.ErrNoRows, so compare
+// with errors.Is(err, drysql.ErrNotFound) or errors.Is(err, sql.ErrNoRows), err == sql.ErrNoRows no longer matches.
+var ErrNotFound error = notFoundError{}
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string {
+	return "drysql: not found, " + sql.ErrNoRows.Error()
+}
+
+func (notFoundError) Unwrap() error {
+	return sql.ErrNoRows
+}
+
+// SqlWarningInterface can optionally be implemented by SqlLogger to receive development time warnings
+type SqlWarningInterface interface {
+	SqlWarning(query string, warning string)
+}
+
+func logSqlWarning(query string, warning string) {
+	if warner, ok := SqlLogger.(SqlWarningInterface); ok {
+		warner.SqlWarning(query, warning)
+	}
+}
+
+type nullValue struct{}
+
+// Null can be assigned to an interface{} field of an update struct to explicitly set that column to NULL.
+// A nil field is skipped by UpdateTableRowFromStruct, a field holding Null is written as NULL.
+var Null interface{} = nullValue{}
+
 func (drysql DrySql) PreparedExec(query string, inputs []interface{}) (sql.Result, error) {
+	return drysql.preparedExecContext(context.Background(), query, inputs)
+}
 
-	stmtOut, err := drysql.sqlImpl.Prepare(query)
+// contextPreparer is implemented by *sql.DB, *sql.Tx and *sql.Conn
+type contextPreparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// preparedExecContext is PreparedExec bounded by ctx, the statement is prepared with ctx when sqlImpl supports it
+func (drysql DrySql) preparedExecContext(ctx context.Context, query string, inputs []interface{}) (sql.Result, error) {
+
+	drysql.requestCache.clear()
+
+	probe, err := drysql.checkCircuit()
+	if err != nil {
+		return nil, err
+	}
+	defer drysql.endProbe(probe)
+
+	start := time.Now()
+	var stmtOut *sql.Stmt
+	if preparer, ok := drysql.sqlImpl.(contextPreparer); ok {
+		stmtOut, err = preparer.PrepareContext(ctx, drysql.traced(query))
+	} else {
+		stmtOut, err = drysql.sqlImpl.Prepare(drysql.traced(query))
+	}
 	if err != nil {
+		drysql.queryFinished(query, inputs, start, err)
 		return nil, err
 	}
 	defer stmtOut.Close()
@@ -40,18 +152,58 @@ func (drysql DrySql) PreparedExec(query string, inputs []interface{}) (sql.Resul
 		SqlLogger.AddSqlWrite()
 	}
 
-	return stmtOut.Exec(inputs...)
+	result, err := stmtOut.ExecContext(ctx, inputs...)
+	drysql.queryFinished(query, inputs, start, err)
+
+	return result, err
 }
 
 func (drysql DrySql) ExecWithoutPrepare(query string, args ...interface{}) (result sql.Result, err error) {
 
-	return drysql.sqlImpl.Exec(query, args)
+	drysql.requestCache.clear()
+
+	probe, err := drysql.checkCircuit()
+	if err != nil {
+		return nil, err
+	}
+	defer drysql.endProbe(probe)
+
+	start := time.Now()
+	result, err = drysql.sqlImpl.Exec(drysql.traced(query), args)
+	drysql.queryFinished(query, args, start, err)
+
+	return result, err
 }
 
 func (drysql DrySql) QueryRow(query string, inputs []interface{}, outputs []interface{}) error {
 
-	stmtOut, err := drysql.sqlImpl.Prepare(query)
+	key, cacheable := drysql.cacheKey("row", query, inputs)
+	if cacheable {
+		if hit, err := drysql.cachedQueryRow(key, outputs); hit {
+			return err
+		}
+	}
+
+	err := drysql.queryRow(query, inputs, outputs)
+	if cacheable {
+		drysql.cacheQueryRow(key, outputs, err)
+	}
+
+	return err
+}
+
+func (drysql DrySql) queryRow(query string, inputs []interface{}, outputs []interface{}) error {
+
+	probe, err := drysql.checkCircuit()
+	if err != nil {
+		return err
+	}
+	defer drysql.endProbe(probe)
+
+	start := time.Now()
+	stmtOut, err := drysql.sqlImpl.Prepare(drysql.traced(query))
 	if err != nil {
+		drysql.queryFinished(query, inputs, start, err)
 		return err
 	}
 	defer stmtOut.Close()
@@ -61,30 +213,91 @@ func (drysql DrySql) QueryRow(query string, inputs []interface{}, outputs []inte
 	}
 
 	row := stmtOut.QueryRow(inputs...)
+	if err = row.Scan(outputs...); err == sql.ErrNoRows {
+		err = ErrNotFound
+	}
+	drysql.queryFinished(query, inputs, start, err)
 
-	return row.Scan(outputs...)
+	return err
 }
 
-func (drysql DrySql) PreparedQuery(query string, inputs []interface{}, scanner func(rows *sql.Rows) error) error {
+// queryFinished is called once the round trip for query has completed, successfully or not
+func (drysql DrySql) queryFinished(query string, inputs []interface{}, start time.Time, err error) {
+
+	duration := time.Since(start)
+	if err == ErrNotFound {
+		// a lookup finding no row is a successful query, not a failure for the stats and metrics
+		err = nil
+	}
+	drysql.logSlowQuery(query, inputs, duration, err)
+	logQueryStats(query, duration, err)
+	drysql.captureQuery(query, inputs, start, duration, err)
+	drysql.detectRepeatedQuery(query)
+	drysql.recordCircuit(err)
+	drysql.metrics.record(query, duration, err)
+}
 
-	stmtOut, err := drysql.sqlImpl.Prepare(query)
+// preparedRows prepares and runs query, the caller must close both the statement and the rows
+func (drysql DrySql) preparedRows(query string, inputs []interface{}) (*sql.Stmt, *sql.Rows, error) {
+	return drysql.preparedStatementRows(query, inputs, false)
+}
+
+// preparedWriteRows is preparedRows for a write returning rows, e.g. INSERT ... RETURNING, counted as a write
+func (drysql DrySql) preparedWriteRows(query string, inputs []interface{}) (*sql.Stmt, *sql.Rows, error) {
+	drysql.requestCache.clear()
+	return drysql.preparedStatementRows(query, inputs, true)
+}
+
+func (drysql DrySql) preparedStatementRows(query string, inputs []interface{}, write bool) (*sql.Stmt, *sql.Rows, error) {
+
+	probe, err := drysql.checkCircuit()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	defer stmtOut.Close()
+	defer drysql.endProbe(probe)
 
-	if SqlLogger != nil {
+	start := time.Now()
+	stmtOut, err := drysql.sqlImpl.Prepare(drysql.traced(query))
+	if err != nil {
+		drysql.queryFinished(query, inputs, start, err)
+		return nil, nil, err
+	}
+
+	if SqlLogger != nil && write {
+		SqlLogger.AddSqlWrite()
+	} else if SqlLogger != nil {
 		SqlLogger.AddSqlRead()
 	}
 
-	var rows *sql.Rows
-	if rows, err = stmtOut.Query(inputs...); err != nil {
-		return err
+	rows, err := stmtOut.Query(inputs...)
+	drysql.queryFinished(query, inputs, start, err)
+	if err != nil {
+		stmtOut.Close()
+		return nil, nil, err
 	}
 
-	if rows != nil {
-		defer rows.Close()
+	return stmtOut, rows, nil
+}
+
+// firstRow advances rows to the first row, returning ErrNotFound when there is none
+func firstRow(rows *sql.Rows) error {
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return ErrNotFound
 	}
+	return nil
+}
+
+func (drysql DrySql) PreparedQuery(query string, inputs []interface{}, scanner func(rows *sql.Rows) error) error {
+
+	stmtOut, rows, err := drysql.preparedRows(query, inputs)
+	if err != nil {
+		return err
+	}
+	defer stmtOut.Close()
+	defer rows.Close()
 
 	for rows.Next() {
 		if err = scanner(rows); err != nil {
@@ -92,13 +305,26 @@ func (drysql DrySql) PreparedQuery(query string, inputs []interface{}, scanner f
 		}
 	}
 
-	return rows.Err()
+	// a Close error can mean the result was cut short, e.g. by a dropped connection, so it is returned rather than ignored
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
 }
 
 func (drysql DrySql) QueryWithoutPrepare(query string, scanner func(rows *sql.Rows) error) (err error) {
 
+	probe, err := drysql.checkCircuit()
+	if err != nil {
+		return err
+	}
+	defer drysql.endProbe(probe)
+
+	start := time.Now()
 	var rows *sql.Rows
-	if rows, err = drysql.sqlImpl.Query(query); err != nil {
+	rows, err = drysql.sqlImpl.Query(drysql.traced(query))
+	drysql.queryFinished(query, nil, start, err)
+	if err != nil {
 		return err
 	}
 
@@ -112,7 +338,10 @@ func (drysql DrySql) QueryWithoutPrepare(query string, scanner func(rows *sql.Ro
 		}
 	}
 
-	return rows.Err()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
 }
 
 // UpdateTableRowFromStruct
@@ -121,7 +350,11 @@ func (drysql DrySql) QueryWithoutPrepare(query string, scanner func(rows *sql.Ro
 // Use the `db:"column_name"` to tag struct fields with column name.  All struct fields must include a db tag
 // rowIdentifierTag identifies which struct field is the row key
 // Only the non-nil values from tagged fields in the struct will be updated.
-// can include an optional fixed conditional params
+// Use drysql.Null in an interface{} field to set a column to NULL.
+// Fields tagged `db:"full_name,readonly"`, e.g. generated columns, are scanned on read but never written.
+// can include an optional fixed conditional params, use UpdateTableRowFromStructWhere to pass parameterized Conditions instead
+// Any scope added with WithScope is also applied to the WHERE clause
+// When there is nothing to update nil is returned, or ErrNoUpdatableFields if created WithStrictUpdates
 
 /* 	EXAMPLE USAGE
 
@@ -129,13 +362,24 @@ func (drysql DrySql) QueryWithoutPrepare(query string, scanner func(rows *sql.Ro
 		UserID `db:"user_id"`
 		FirstName *string `db:"first_name"`
 		LastName *string `db:"last_name"`
-	}
+		DeletedAt interface{} `db:"deleted_at"`
+	}{UserID: 1, DeletedAt: drysql.Null}
 
 	err = drysql.UpdateTableRowFromStruct("my_users", "user_id", userUpdate)
 */
 
 func (drysql DrySql) UpdateTableRowFromStruct(tableName string, rowIdentifierTag string, updateStruct interface{}, optionalConditional string) (err error) {
 
+	return drysql.UpdateTableRowFromStructWhere(tableName, rowIdentifierTag, updateStruct, Where(optionalConditional))
+}
+
+// UpdateTableRowFromStructWhere is UpdateTableRowFromStruct with the row additionally matched by conditions
+func (drysql DrySql) UpdateTableRowFromStructWhere(tableName string, rowIdentifierTag string, updateStruct interface{}, conditions ...Condition) (err error) {
+
+	if err = validate(updateStruct); err != nil {
+		return err
+	}
+
 	var columnsToUpdate string
 	var inputs []interface{}
 	var rowIdentifierValue interface{}
@@ -144,22 +388,36 @@ func (drysql DrySql) UpdateTableRowFromStruct(tableName string, rowIdentifierTag
 
 	// Iterate over all available fields and read the tag value
 	for i := 0; i < t.NumField(); i++ {
-		columnValue, err := driver.DefaultParameterConverter.ConvertValue(v.Field(i).Interface())
-		if err != nil {
-			return err
+		// jsonagg fields are derived by the query and never written, untagged and unexported fields aren't columns
+		if tag := parseTag(t.Field(i)); tag.name == "" || tag.has("jsonagg") {
+			continue
 		}
-		if columnValue != nil {
+		fieldValue := v.Field(i).Interface()
+		isNull := fieldValue == Null
+		var columnValue driver.Value
+		if !isNull {
+			if columnValue, err = driver.DefaultParameterConverter.ConvertValue(fieldValue); err != nil {
+				return err
+			}
+		}
+		if columnValue != nil || isNull {
 			// Get the field, returns https://golang.org/pkg/reflect/#StructField
 			field := t.Field(i)
-			columnKey := field.Tag.Get("db")
+			tag := parseTag(field)
+			columnKey := tag.name
 			if columnKey != "" {
+				if columnValue != nil {
+					if columnValue, err = drysql.encodeColumn(tag, columnValue); err != nil {
+						return err
+					}
+				}
 				if strings.EqualFold(columnKey, rowIdentifierTag) {
 					rowIdentifierValue = columnValue
-				} else {
+				} else if !tag.has("readonly") {
 					if len(columnsToUpdate) != 0 {
 						columnsToUpdate += ", "
 					}
-					columnsToUpdate += columnKey + " = ?"
+					columnsToUpdate += drysql.quote(columnKey) + " = ?"
 					inputs = append(inputs, columnValue)
 				}
 			}
@@ -167,19 +425,26 @@ func (drysql DrySql) UpdateTableRowFromStruct(tableName string, rowIdentifierTag
 	}
 
 	if len(inputs) == 0 {
+		if drysql.strictUpdates {
+			return ErrNoUpdatableFields
+		}
 		return nil
 	}
 
-	if len(optionalConditional) > 0 {
-		optionalConditional = " AND " + optionalConditional
-	}
-
 	inputs = append(inputs, rowIdentifierValue)
 
-	query := "UPDATE " + tableName + " SET " + columnsToUpdate + " WHERE " + rowIdentifierTag + " = ?" + optionalConditional
+	var conditional string
+	if clause, args := drysql.conditionClause(conditions); len(clause) > 0 {
+		conditional = " AND " + clause
+		inputs = append(inputs, args...)
+	}
+
+	tableName = drysql.tableName(tableName, updateStruct)
+	drysql.warnOnTypeMismatch(tableName, append([]Condition{Where(rowIdentifierTag+" = ?", rowIdentifierValue)}, conditions...))
+	query := "UPDATE " + drysql.quote(tableName) + " SET " + columnsToUpdate + " WHERE " + drysql.quote(rowIdentifierTag) + " = ?" + conditional
 
 	// don't use a prepared statement as reuse is less likely with these dynamic queries
-	_, err = drysql.PreparedExec(query, inputs)
+	_, err = drysql.PreparedExec(drysql.rebind(query), inputs)
 
 	return err
 }
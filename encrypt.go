@@ -0,0 +1,51 @@
+package drysql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+var ErrNoEncryptor = errors.New("drysql: encrypted field without an encryptor, see WithEncryptor")
+
+// FieldEncryptor encrypts and decrypts the values of fields tagged `db:"column_name,encrypted"`
+type FieldEncryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// WithEncryptor returns a copy of drysql that encrypts encrypted fields before binding them in the write helpers
+// and decrypts them after scanning in the scan helpers.  Encrypted fields must be a string or []byte, or a pointer to one.
+// Writing or scanning an encrypted field without an encryptor returns ErrNoEncryptor so plaintext is never written.
+func (drysql DrySql) WithEncryptor(encryptor FieldEncryptor) DrySql {
+	drysql.encryptor = encryptor
+	drysql.decoderID = newDecoderID()
+	return drysql
+}
+
+func (drysql DrySql) encrypt(tag dbTag, value driver.Value) (driver.Value, error) {
+
+	if drysql.encryptor == nil {
+		return nil, ErrNoEncryptor
+	}
+
+	plaintext, ok := srcBytes(value)
+	if !ok {
+		return nil, fmt.Errorf("drysql: encrypted column %s must be a string or []byte", tag.name)
+	}
+	if plaintext == nil {
+		// a nil []byte is bound as NULL, keep it that way
+		return value, nil
+	}
+
+	return drysql.encryptor.Encrypt(plaintext)
+}
+
+func (drysql DrySql) decrypt(ciphertext []byte) ([]byte, error) {
+
+	if drysql.encryptor == nil {
+		return nil, ErrNoEncryptor
+	}
+
+	return drysql.encryptor.Decrypt(ciphertext)
+}
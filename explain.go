@@ -0,0 +1,218 @@
+package drysql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WithFullScanWarnings returns a copy of drysql that runs EXPLAIN before each struct helper SELECT and
+// logs a warning through SqlLogger when the plan contains a full table scan over at least minRows estimated rows.
+// Detects MySQL type=ALL and Postgres Seq Scan plans.  Every query costs an extra round trip so only enable this during development.
+func (drysql DrySql) WithFullScanWarnings(minRows int64) DrySql {
+	drysql.fullScanWarnings = true
+	drysql.fullScanMinRows = minRows
+	return drysql
+}
+
+var ErrNotSelect = errors.New("drysql: statement is not a SELECT")
+
+// WithExplainAnalyzeWrites returns a copy of drysql whose ExplainAnalyze also runs non-SELECT statements
+func (drysql DrySql) WithExplainAnalyzeWrites() DrySql {
+	drysql.explainWrites = true
+	return drysql
+}
+
+var seqScanPattern = regexp.MustCompile(`Seq Scan on (\S+).*rows=(\d+)`)
+
+// explain runs prefix + query and returns every row of the plan with values converted to strings
+func (drysql DrySql) explain(prefix string, query string, inputs []interface{}) (columns []string, plan [][]string, err error) {
+
+	stmtOut, err := drysql.sqlImpl.Prepare(prefix + query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer stmtOut.Close()
+
+	rows, err := stmtOut.Query(inputs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	if columns, err = rows.Columns(); err != nil {
+		return nil, nil, err
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		targets := make([]interface{}, len(columns))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err = rows.Scan(targets...); err != nil {
+			return nil, nil, err
+		}
+
+		row := make([]string, len(columns))
+		for i, value := range values {
+			switch value := value.(type) {
+			case nil:
+			case []byte:
+				row[i] = string(value)
+			default:
+				row[i] = fmt.Sprint(value)
+			}
+		}
+		plan = append(plan, row)
+	}
+
+	return columns, plan, rows.Err()
+}
+
+func (drysql DrySql) warnOnFullScan(query string, inputs []interface{}) {
+
+	if !drysql.fullScanWarnings {
+		return
+	}
+
+	columns, plan, err := drysql.explain("EXPLAIN ", query, inputs)
+	if err != nil {
+		logSqlWarning(query, "EXPLAIN failed: "+err.Error())
+		return
+	}
+
+	typeColumn, tableColumn, rowsColumn := -1, -1, -1
+	for i, column := range columns {
+		switch strings.ToLower(column) {
+		case "type":
+			typeColumn = i
+		case "table":
+			tableColumn = i
+		case "rows":
+			rowsColumn = i
+		}
+	}
+
+	for _, row := range plan {
+		var table, estimated string
+		if typeColumn >= 0 && strings.EqualFold(row[typeColumn], "ALL") {
+			// MySQL tabular plan
+			if tableColumn >= 0 {
+				table = row[tableColumn]
+			}
+			if rowsColumn >= 0 {
+				estimated = row[rowsColumn]
+			}
+		} else if match := seqScanPattern.FindStringSubmatch(strings.Join(row, " ")); match != nil {
+			// Postgres text plan
+			table, estimated = match[1], match[2]
+		} else {
+			continue
+		}
+
+		estimatedRows, _ := strconv.ParseInt(estimated, 10, 64)
+		if estimatedRows >= drysql.fullScanMinRows {
+			logSqlWarning(query, fmt.Sprintf("full table scan on %s (estimated %d rows)", table, estimatedRows))
+		}
+	}
+}
+
+// ExplainAnalyze runs EXPLAIN ANALYZE on query (Postgres, MySQL 8.0.18+) and returns the plan with actual execution stats,
+// one line per plan row.  Unlike EXPLAIN this executes the query, including any side effects it has,
+// so anything other than a single read only SELECT, including a WITH with a data modifying CTE, returns ErrNotSelect unless drysql was created WithExplainAnalyzeWrites.
+func (drysql DrySql) ExplainAnalyze(query string, inputs []interface{}) (string, error) {
+
+	if !drysql.explainWrites && !drysql.isSelect(query) {
+		return "", ErrNotSelect
+	}
+
+	_, plan, err := drysql.explain("EXPLAIN ANALYZE ", query, inputs)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, len(plan))
+	for i, row := range plan {
+		lines[i] = strings.Join(row, "\t")
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// isSelect reports whether query is a single read only SELECT, a SELECT or WITH with no INSERT, UPDATE, DELETE or MERGE
+// anywhere in it, e.g. in a data modifying CTE, no SELECT ... INTO and nothing after a terminating semicolon.
+// Locking reads, FOR UPDATE and FOR NO KEY UPDATE, are still selects.
+func (drysql DrySql) isSelect(query string) bool {
+
+	keywords, _, trailing := drysql.statementWords(query)
+	if trailing {
+		return false
+	}
+
+	if len(keywords) == 0 || keywords[0] != "SELECT" && keywords[0] != "WITH" {
+		return false
+	}
+	for i, keyword := range keywords {
+		switch keyword {
+		case "INSERT", "DELETE", "MERGE", "INTO":
+			return false
+		case "UPDATE":
+			if previous := keywords[i-1]; previous != "FOR" && previous != "KEY" {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// statementWords returns the upper cased words and numbers of query up to its first semicolon, leaving out literals and
+// comments, whether there is a semicolon, and whether anything other than whitespace follows it
+func (drysql DrySql) statementWords(query string) (words []string, terminated bool, trailing bool) {
+
+	for i := 0; i < len(query); i++ {
+		if end := drysql.literalEnd(query, i); end > i {
+			if terminated {
+				return words, true, true
+			}
+			i = end - 1
+			continue
+		}
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		case terminated:
+			return words, true, true
+		case c == ';':
+			terminated = true
+		case isNameStart(c) || c >= '0' && c <= '9':
+			end := i + 1
+			for end < len(query) && (isNameStart(query[end]) || query[end] >= '0' && query[end] <= '9') {
+				end++
+			}
+			words = append(words, strings.ToUpper(query[i:end]))
+			i = end - 1
+		}
+	}
+
+	return words, terminated, false
+}
+
+// hasLockingClause reports whether the statementWords of a query include FOR UPDATE, FOR SHARE, FOR NO KEY UPDATE,
+// FOR KEY SHARE or LOCK IN SHARE MODE
+func hasLockingClause(words []string) bool {
+	for i, word := range words {
+		switch word {
+		case "LOCK":
+			return true
+		case "FOR":
+			if i+1 < len(words) && (words[i+1] == "UPDATE" || words[i+1] == "SHARE" || words[i+1] == "NO" || words[i+1] == "KEY") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package drysql
+
+import (
+	"testing"
+)
+
+func TestIsSelect(t *testing.T) {
+
+	tests := map[string]bool{
+		"SELECT a FROM t": true,
+		"  (SELECT a FROM t) UNION (SELECT b FROM u)": true,
+		"select a from t;": true,
+		"WITH r AS (SELECT a FROM t) SELECT a FROM r":                true,
+		"SELECT a FROM t WHERE note = 'delete me' -- update":         true,
+		"SELECT a FROM t FOR UPDATE":                                 true,
+		"SELECT a FROM t FOR NO KEY UPDATE":                          true,
+		"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d":      false,
+		"WITH u AS (UPDATE t SET a = 1 RETURNING a) SELECT a FROM u": false,
+		"SELECT a INTO backup FROM t":                                false,
+		"SELECT a FROM t; DELETE FROM t":                             false,
+		"SELECT a FROM t; 'x'":                                       false,
+		"UPDATE t SET a = 1":                                         false,
+		"EXPLAIN SELECT a FROM t":                                    false,
+		"":                                                           false,
+	}
+
+	for query, want := range tests {
+		if got := (DrySql{dialect: Postgres}).isSelect(query); got != want {
+			t.Errorf("isSelect(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package drysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDB is a database/sql driver answering queries with canned results, recording every statement it runs
+type fakeDB struct {
+	mutex   sync.Mutex
+	results map[string]fakeResult // keyed by query prefix, the longest matching prefix wins
+	log     []string
+	dialErr error // returned by Connect while set
+}
+
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// newFakeDB returns a fake database and a DrySql running on it, closed when the test ends
+func newFakeDB(t *testing.T) (*fakeDB, DrySql) {
+	fake := &fakeDB{results: make(map[string]fakeResult)}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { db.Close() })
+	return fake, GetDrySqlImplementation(db)
+}
+
+// setRows answers queries starting with prefix with columns and rows
+func (fake *fakeDB) setRows(prefix string, columns []string, rows ...[]driver.Value) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.results[prefix] = fakeResult{columns: columns, rows: rows}
+}
+
+// statements returns the statements run so far, each as its query followed by its args
+func (fake *fakeDB) statements() []string {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	return append([]string(nil), fake.log...)
+}
+
+// failConnecting makes every new connection fail with err until it is called with nil
+func (fake *fakeDB) failConnecting(err error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.dialErr = err
+}
+
+func (fake *fakeDB) record(statement string) {
+	fake.mutex.Lock()
+	fake.log = append(fake.log, statement)
+	fake.mutex.Unlock()
+}
+
+func (fake *fakeDB) Connect(context.Context) (driver.Conn, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if fake.dialErr != nil {
+		return nil, fake.dialErr
+	}
+	return fakeConn{fake}, nil
+}
+
+func (fake *fakeDB) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	fake *fakeDB
+}
+
+func (conn fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{fake: conn.fake, query: query}, nil
+}
+
+func (conn fakeConn) Close() error {
+	return nil
+}
+
+func (conn fakeConn) Begin() (driver.Tx, error) {
+	conn.fake.record("BEGIN")
+	return fakeTx{conn.fake}, nil
+}
+
+func (conn fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	conn.fake.record(fmt.Sprintf("BEGIN isolation=%d readonly=%v", opts.Isolation, opts.ReadOnly))
+	return fakeTx{conn.fake}, nil
+}
+
+type fakeTx struct {
+	fake *fakeDB
+}
+
+func (tx fakeTx) Commit() error {
+	tx.fake.record("COMMIT")
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.fake.record("ROLLBACK")
+	return nil
+}
+
+type fakeStmt struct {
+	fake  *fakeDB
+	query string
+}
+
+func (stmt fakeStmt) Close() error {
+	return nil
+}
+
+func (stmt fakeStmt) NumInput() int {
+	return -1
+}
+
+func (stmt fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	stmt.fake.record(fmt.Sprint(stmt.query, " ", args))
+	return driver.RowsAffected(1), nil
+}
+
+func (stmt fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+
+	stmt.fake.record(fmt.Sprint(stmt.query, " ", args))
+
+	stmt.fake.mutex.Lock()
+	defer stmt.fake.mutex.Unlock()
+	longest := ""
+	for prefix := range stmt.fake.results {
+		if strings.HasPrefix(stmt.query, prefix) && len(prefix) >= len(longest) {
+			longest = prefix
+		}
+	}
+	result, ok := stmt.fake.results[longest]
+	if !ok {
+		return nil, fmt.Errorf("fake: no rows set for %q", stmt.query)
+	}
+
+	return &fakeRows{result: result}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	next   int
+}
+
+func (rows *fakeRows) Columns() []string {
+	return rows.result.columns
+}
+
+func (rows *fakeRows) Close() error {
+	return nil
+}
+
+func (rows *fakeRows) Next(dest []driver.Value) error {
+	if rows.next >= len(rows.result.rows) {
+		return io.EOF
+	}
+	copy(dest, rows.result.rows[rows.next])
+	rows.next++
+	return nil
+}
@@ -0,0 +1,60 @@
+package drysql
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var placeholderListPattern = regexp.MustCompile(`\?(\s*,\s*\?)+`)
+
+// QueryFingerprint normalizes a query so that executions differing only in literal values share a fingerprint.
+// String and numeric literals and $N placeholders become ?, lists of placeholders collapse to ?+,
+// whitespace is collapsed and the result is lower cased, e.g. "SELECT * FROM t WHERE id IN (1, 2,3)" -> "select * from t where id in (?+)"
+func QueryFingerprint(query string) string {
+
+	var fingerprint strings.Builder
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			// skip to the closing quote, '' and \' are escaped quotes
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' {
+					i++
+				} else if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+					} else {
+						break
+					}
+				}
+			}
+			fingerprint.WriteRune('?')
+		case r == '$' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			for i+1 < len(runes) && unicode.IsDigit(runes[i+1]) {
+				i++
+			}
+			fingerprint.WriteRune('?')
+		case unicode.IsDigit(r) && (i == 0 || !isIdentifierRune(runes[i-1])):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			fingerprint.WriteRune('?')
+		case unicode.IsSpace(r):
+			for i+1 < len(runes) && unicode.IsSpace(runes[i+1]) {
+				i++
+			}
+			fingerprint.WriteRune(' ')
+		default:
+			fingerprint.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return placeholderListPattern.ReplaceAllString(strings.TrimSpace(fingerprint.String()), "?+")
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || r == '$' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,23 @@
+package drysql
+
+import (
+	"testing"
+)
+
+func TestQueryFingerprint(t *testing.T) {
+
+	tests := map[string]string{
+		"SELECT * FROM t WHERE id IN (1, 2,3)":                "select * from t where id in (?+)",
+		"SELECT * FROM t WHERE id IN (?, ?, ?)":               "select * from t where id in (?+)",
+		"SELECT * FROM t WHERE name = 'O''Brien' AND x = 1.5": "select * from t where name = ? and x = ?",
+		"SELECT *\n\tFROM  t WHERE a = $1 AND b = $12":        "select * from t where a = ? and b = ?",
+		"SELECT col2 FROM t2 WHERE a = 'it\\'s'":              "select col2 from t2 where a = ?",
+		"  select 1  ":                                        "select ?",
+	}
+
+	for query, want := range tests {
+		if got := QueryFingerprint(query); got != want {
+			t.Errorf("QueryFingerprint(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package drysql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// QueryIntoGroups collapses the flat rows of a one-to-many join into parents with nested children.
+// dest is a *[]Parent or *[]*Parent, parentKey is the column identifying a parent and childrenField is the name
+// of the Parent field holding a []Child or []*Child.  Both Parent and Child fields are matched to columns by db tag,
+// rows sharing a parentKey value are appended to the same parent in the order they are returned.
+// A child whose fields are all zero, e.g. from a LEFT JOIN without a match, is not appended (its fields must be nullable types to scan NULL).
+
+/* 	EXAMPLE USAGE
+
+	type Order struct {
+		OrderID int64      `db:"order_id"`
+		Lines   []OrderLine
+	}
+	type OrderLine struct {
+		LineID int64  `db:"line_id"`
+		Sku    string `db:"sku"`
+	}
+
+	var orders []Order
+	err = drysql.QueryIntoGroups("SELECT o.order_id, l.line_id, l.sku FROM orders o JOIN order_lines l ON l.order_id = o.order_id",
+		nil, "order_id", "Lines", &orders)
+*/
+
+func (drysql DrySql) QueryIntoGroups(query string, inputs []interface{}, parentKey string, childrenField string, dest interface{}) error {
+
+	slice, parentType, isPtr, err := sliceDestination(dest)
+	if err != nil {
+		return err
+	}
+
+	keyIndex, ok := taggedField(parentType, parentKey)
+	if !ok {
+		return fmt.Errorf("drysql: %s has no field tagged %q", parentType, parentKey)
+	}
+
+	children, ok := parentType.FieldByName(childrenField)
+	if !ok || children.Type.Kind() != reflect.Slice {
+		return fmt.Errorf("drysql: %s has no slice field %s", parentType, childrenField)
+	}
+	childType := children.Type.Elem()
+	childIsPtr := childType.Kind() == reflect.Ptr
+	if childIsPtr {
+		childType = childType.Elem()
+	}
+	if childType.Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+
+	drysql.warnOnFullScan(query, inputs)
+
+	parents := make(map[interface{}]int)
+	var parentScanner, childScanner *structScanner
+	var scanErrors ScanErrors
+	row := -1
+	err = drysql.PreparedQuery(query, inputs, func(rows *sql.Rows) error {
+		row++
+		if parentScanner == nil {
+			columns, err := rows.Columns()
+			if err != nil {
+				return err
+			}
+			parentScanner = drysql.newStructScanner(columns, parentType)
+			childScanner = drysql.newStructScanner(columns, childType)
+		}
+
+		parent := reflect.New(parentType)
+		if err := parentScanner.scan(rows, parent.Elem()); err != nil {
+			return drysql.skipScanError(&scanErrors, row, err)
+		}
+		child := reflect.New(childType)
+		if err := childScanner.scan(rows, child.Elem()); err != nil {
+			return drysql.skipScanError(&scanErrors, row, err)
+		}
+
+		key := groupKey(parent.Elem().Field(keyIndex))
+		index, seen := parents[key]
+		if !seen {
+			index = slice.Len()
+			parents[key] = index
+			if isPtr {
+				slice.Set(reflect.Append(slice, parent))
+			} else {
+				slice.Set(reflect.Append(slice, parent.Elem()))
+			}
+		}
+
+		if child.Elem().IsZero() {
+			return nil
+		}
+
+		target := slice.Index(index)
+		if isPtr {
+			target = target.Elem()
+		}
+		target = target.FieldByIndex(children.Index)
+		if childIsPtr {
+			target.Set(reflect.Append(target, child))
+		} else {
+			target.Set(reflect.Append(target, child.Elem()))
+		}
+		return nil
+	})
+
+	if err == nil && len(scanErrors) > 0 {
+		return scanErrors
+	}
+	return err
+}
+
+// groupKey returns a comparable map key for a parent key field, dereferencing pointers so parents are grouped by value
+func groupKey(field reflect.Value) interface{} {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	if bytes, ok := field.Interface().([]byte); ok {
+		return string(bytes)
+	}
+	return field.Interface()
+}
@@ -0,0 +1,228 @@
+package drysql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// insertField is a db tagged struct field written by the insert helpers
+type insertField struct {
+	index int
+	tag   dbTag
+}
+
+// insertFields returns the db tagged fields of structType in field order, leaving out readonly and jsonagg fields
+func insertFields(structType reflect.Type) []insertField {
+
+	var fields []insertField
+	for i := 0; i < structType.NumField(); i++ {
+		if tag := parseTag(structType.Field(i)); tag.name != "" && !tag.has("readonly") && !tag.has("jsonagg") {
+			fields = append(fields, insertField{index: i, tag: tag})
+		}
+	}
+
+	return fields
+}
+
+// isAutoIncrement reports whether the field is assigned by the database on insert, tagged autoincrement or pk,auto
+func isAutoIncrement(tag dbTag) bool {
+	return tag.has("autoincrement") || tag.has("pk") && tag.has("auto")
+}
+
+// insertValues returns the values bound for the fields of v, nil pointers and Null are bound as NULL
+func (drysql DrySql) insertValues(v reflect.Value, fields []insertField) ([]interface{}, error) {
+
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		fieldValue := v.Field(field.index).Interface()
+		if fieldValue == Null {
+			continue
+		}
+
+		columnValue, err := driver.DefaultParameterConverter.ConvertValue(fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		if columnValue != nil {
+			if columnValue, err = drysql.encodeColumn(field.tag, columnValue); err != nil {
+				return nil, err
+			}
+		}
+		values[i] = columnValue
+	}
+
+	return values, nil
+}
+
+// BuildInsert returns the INSERT statement the insert helpers run for insertStruct, without running it, along with the
+// columns it writes and the args to bind.  columns are in struct field order, and args and the query's placeholders follow
+// that same order, so tests can assert exactly what a struct writes without parsing the SQL.
+// readonly and autoincrement fields are left out, nil pointers and Null are bound as NULL.
+
+/* 	EXAMPLE USAGE
+
+	query, columns, args, err := drysql.BuildInsert("my_users", User{UserID: 1, FirstName: "Ann"})
+	// columns == []string{"user_id", "first_name"}, args == []interface{}{int64(1), "Ann"}
+*/
+
+func (drysql DrySql) BuildInsert(tableName string, insertStruct interface{}) (query string, columns []string, args []interface{}, err error) {
+
+	v := reflect.Indirect(reflect.ValueOf(insertStruct))
+	if v.Kind() != reflect.Struct {
+		return "", nil, nil, ErrInvalidDestination
+	}
+	if err = validate(insertStruct); err != nil {
+		return "", nil, nil, err
+	}
+
+	var fields []insertField
+	for _, field := range insertFields(v.Type()) {
+		if !isAutoIncrement(field.tag) {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return "", nil, nil, ErrInvalidDestination
+	}
+	columns = make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = field.tag.name
+	}
+	if args, err = drysql.insertValues(v, fields); err != nil {
+		return "", nil, nil, err
+	}
+
+	query = "INSERT INTO " + drysql.quote(drysql.tableName(tableName, insertStruct)) + " (" + strings.Join(drysql.quoteAll(columns), ", ") +
+		") VALUES (" + strings.Repeat("?, ", len(fields)-1) + "?)"
+
+	return drysql.rebind(query), columns, args, nil
+}
+
+// InsertTableRowFromStruct inserts the db tagged fields of insertStruct into tableName, see BuildInsert.  A field tagged
+// autoincrement, or pk,auto, is left out of the insert even when zero so the database assigns it, and when insertStruct is
+// a pointer the generated id is written back into that field, from LastInsertId or from a RETURNING clause on Postgres.
+
+/* 	EXAMPLE USAGE
+
+	type User struct {
+		UserID    int64  `db:"user_id,pk,auto"`
+		FirstName string `db:"first_name"`
+	}
+
+	user := User{FirstName: "Ann"}
+	err = drysql.InsertTableRowFromStruct("my_users", &user)
+	// user.UserID holds the generated id
+*/
+
+func (drysql DrySql) InsertTableRowFromStruct(tableName string, insertStruct interface{}) error {
+
+	query, _, inputs, err := drysql.BuildInsert(tableName, insertStruct)
+	if err != nil {
+		return err
+	}
+
+	var idField reflect.Value
+	if v := reflect.ValueOf(insertStruct); v.Kind() == reflect.Ptr {
+		for _, field := range insertFields(v.Elem().Type()) {
+			if isAutoIncrement(field.tag) {
+				idField = v.Elem().Field(field.index)
+				if drysql.dialect == Postgres {
+					// the clause has no placeholders so it can follow the already rebound insert
+					query += " RETURNING " + drysql.quote(field.tag.name)
+				}
+				break
+			}
+		}
+	}
+	if !idField.IsValid() {
+		_, err = drysql.PreparedExec(query, inputs)
+		return err
+	}
+
+	var id int64
+	if drysql.dialect == Postgres {
+		err = drysql.insertReturning(query, inputs, &id)
+	} else {
+		id, err = drysql.PreparedExecResult(query, inputs).LastInsertId()
+	}
+	if err != nil {
+		return err
+	}
+
+	switch idField.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		idField.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		idField.SetUint(uint64(id))
+	default:
+		return fmt.Errorf("drysql: cannot write the generated id into a field of type %s", idField.Type())
+	}
+
+	return nil
+}
+
+// insertReturning runs an INSERT ... RETURNING as a write, scanning the returned column into id
+func (drysql DrySql) insertReturning(query string, inputs []interface{}, id *int64) error {
+
+	stmtOut, rows, err := drysql.preparedWriteRows(query, inputs)
+	if err != nil {
+		return err
+	}
+	defer stmtOut.Close()
+	defer rows.Close()
+
+	if err = firstRow(rows); err != nil {
+		return err
+	}
+	if err = rows.Scan(id); err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
+// InsertFromStructIdempotent inserts the db tagged fields of insertStruct into tableName unless a row with the same
+// idempotencyColumn value already exists, so a write retried after an ambiguous failure is never applied twice.
+// idempotencyColumn must have a unique constraint, duplicate reports whether the row had already been inserted.
+// MySQL ignores a duplicate of any unique key of the table, not only idempotencyColumn.
+// Returns ErrUpsertScoped with a scope added WithScope, a duplicate outside the scope would be reported as already inserted.
+
+/* 	EXAMPLE USAGE
+
+	payment := Payment{IdempotencyKey: job.ID, UserID: job.UserID, Amount: job.Amount}
+	duplicate, err := drysql.InsertFromStructIdempotent("payments", payment, "idempotency_key")
+	if err == nil && duplicate {
+		// already processed by an earlier attempt
+	}
+*/
+
+func (drysql DrySql) InsertFromStructIdempotent(tableName string, insertStruct interface{}, idempotencyColumn string) (duplicate bool, err error) {
+
+	v := reflect.Indirect(reflect.ValueOf(insertStruct))
+	if v.Kind() != reflect.Struct {
+		return false, ErrInvalidDestination
+	}
+	if len(drysql.scope) > 0 {
+		return false, ErrUpsertScoped
+	}
+	if _, ok := taggedField(v.Type(), idempotencyColumn); !ok {
+		return false, fmt.Errorf("drysql: %s has no field tagged %q", v.Type(), idempotencyColumn)
+	}
+
+	query, _, inputs, err := drysql.BuildInsert(tableName, insertStruct)
+	if err != nil {
+		return false, err
+	}
+
+	// the conflict clause has no placeholders so it can follow the already rebound insert
+	query += drysql.upsertClause(drysql.tableName(tableName, insertStruct), []string{idempotencyColumn}, []string{idempotencyColumn})
+
+	rowsAffected, err := drysql.PreparedExecResult(query, inputs).RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 0, nil
+}
@@ -0,0 +1,49 @@
+package drysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+type countingLogger struct {
+	reads, writes int
+}
+
+func (logger *countingLogger) AddSqlRead()  { logger.reads++ }
+func (logger *countingLogger) AddSqlWrite() { logger.writes++ }
+
+func TestInsertTableRowFromStructReturningIsAWrite(t *testing.T) {
+
+	type user struct {
+		UserID    int64  `db:"user_id,pk,auto"`
+		FirstName string `db:"first_name"`
+	}
+
+	logger := &countingLogger{}
+	SqlLogger = logger
+	defer func() { SqlLogger = nil }()
+
+	fake, appDB := newFakeDB(t)
+	fake.setRows("INSERT", []string{"user_id"}, []driver.Value{int64(9)})
+	fake.setRows("SELECT", []string{"first_name"}, []driver.Value{[]byte("Ann")})
+	db := appDB.WithDialect(Postgres).WithRequestCache(ContextWithRequestCache(context.Background()))
+
+	db.QueryRowString("SELECT first_name FROM my_users", nil)
+	inserted := user{FirstName: "Ann"}
+	if err := db.InsertTableRowFromStruct("my_users", &inserted); err != nil {
+		t.Fatal(err)
+	}
+	if inserted.UserID != 9 {
+		t.Errorf("generated id = %d, want 9", inserted.UserID)
+	}
+	db.QueryRowString("SELECT first_name FROM my_users", nil)
+
+	if logger.reads != 2 || logger.writes != 1 {
+		t.Errorf("counted %d reads and %d writes, want 2 and 1", logger.reads, logger.writes)
+	}
+	want := "INSERT INTO my_users (first_name) VALUES ($1) RETURNING user_id [Ann]"
+	if got := fake.statements(); len(got) != 3 || got[1] != want {
+		t.Errorf("ran %q, want the insert %q and the read after it to query again", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package drysql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// jsonAggDecoder decodes a JSON array column, e.g. from json_agg or JSON_ARRAYAGG, into a field tagged `db:"items,jsonagg"`.
+// The keys of each object are matched to the db tags of a []Child or []*Child element type, the same way columns are,
+// so a child struct scanned elsewhere as a row needs no json tags.  Any other field type is decoded with json.Unmarshal.
+// A NULL column, e.g. json_agg over no rows, leaves the field nil.
+func (drysql DrySql) jsonAggDecoder(src interface{}, dest reflect.Value) error {
+
+	if src == nil {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+	data, ok := srcBytes(src)
+	if !ok {
+		return fmt.Errorf("drysql: cannot decode %T into %s", src, dest.Type())
+	}
+
+	elemType := dest.Type()
+	if elemType.Kind() != reflect.Slice {
+		return json.Unmarshal(data, dest.Addr().Interface())
+	}
+	elemType = elemType.Elem()
+	elemIsPtr := elemType.Kind() == reflect.Ptr
+	if elemIsPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct || elemType == timeType {
+		return json.Unmarshal(data, dest.Addr().Interface())
+	}
+
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return err
+	}
+
+	tagged := make(map[string]int)
+	for i := 0; i < elemType.NumField(); i++ {
+		if name := parseTag(elemType.Field(i)).name; name != "" {
+			tagged[drysql.columnMatchKey(name)] = i
+		}
+	}
+
+	slice := reflect.MakeSlice(dest.Type(), 0, len(objects))
+	for _, object := range objects {
+		elem := reflect.New(elemType)
+		for key, value := range object {
+			if index, ok := tagged[drysql.columnMatchKey(key)]; ok {
+				if err := json.Unmarshal(value, elem.Elem().Field(index).Addr().Interface()); err != nil {
+					return fmt.Errorf("drysql: decoding %q of %s: %v", key, elemType, err)
+				}
+			}
+		}
+		if elemIsPtr {
+			slice = reflect.Append(slice, elem)
+		} else {
+			slice = reflect.Append(slice, elem.Elem())
+		}
+	}
+	dest.Set(slice)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package drysql
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// KeyRange returns the lowest and highest keyTag values across the slice of structs, e.g. the ids a batch passed to
+// BatchUpsertFromStructs covered, so a resumable job can checkpoint "processed ids 1000-2000".
+// Values are compared as their driver values, integers as int64, so min and max hold driver values too.
+// NULL keys are skipped, min and max are nil when structs is empty or every key is NULL.
+
+/* 	EXAMPLE USAGE
+
+	if _, err = drysql.BatchUpsertFromStructs("my_users", []string{"user_id"}, users); err != nil {
+		return err
+	}
+	first, last, err := drysql.KeyRange(users, "user_id")
+*/
+
+func KeyRange(structs interface{}, keyTag string) (min interface{}, max interface{}, err error) {
+
+	v := reflect.Indirect(reflect.ValueOf(structs))
+	structType := indirectType(structs)
+	if v.Kind() != reflect.Slice || structType == nil || structType.Kind() != reflect.Struct {
+		return nil, nil, ErrInvalidDestination
+	}
+	keyIndex, ok := taggedField(structType, keyTag)
+	if !ok {
+		return nil, nil, fmt.Errorf("drysql: %s has no field tagged %q", structType, keyTag)
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		elem := reflect.Indirect(v.Index(i))
+		if !elem.IsValid() {
+			continue
+		}
+		key, err := driver.DefaultParameterConverter.ConvertValue(elem.Field(keyIndex).Interface())
+		if err != nil {
+			return nil, nil, err
+		}
+		if key == nil {
+			continue
+		}
+
+		if min == nil {
+			min, max = key, key
+			continue
+		}
+		if less, err := keyLess(key, min); err != nil {
+			return nil, nil, err
+		} else if less {
+			min = key
+		}
+		if less, _ := keyLess(max, key); less {
+			max = key
+		}
+	}
+
+	return min, max, nil
+}
+
+// keyLess reports whether the driver value a sorts before b, both must be of the same type
+func keyLess(a interface{}, b interface{}) (bool, error) {
+	switch a := a.(type) {
+	case int64:
+		if b, ok := b.(int64); ok {
+			return a < b, nil
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			return a < b, nil
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return a < b, nil
+		}
+	case []byte:
+		if b, ok := b.([]byte); ok {
+			return bytes.Compare(a, b) < 0, nil
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Before(b), nil
+		}
+	}
+	return false, fmt.Errorf("drysql: cannot compare keys of type %T and %T", a, b)
+}
@@ -0,0 +1,73 @@
+package drysql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// WithUnknownKeyErrors returns a copy of drysql whose QueryKeyValuesIntoStruct returns an error for a key
+// that does not match any db tagged field, rather than ignoring it
+func (drysql DrySql) WithUnknownKeyErrors() DrySql {
+	drysql.unknownKeyErrors = true
+	return drysql
+}
+
+// QueryKeyValuesIntoStruct collapses a query returning (key, value) rows into the struct dest points to,
+// scanning each value into the field whose db tag matches the key.  Values are converted to the field's type by the
+// usual database/sql scan conversions, e.g. a '30' value scans into an int field.  Unknown keys are ignored unless created WithUnknownKeyErrors.
+
+/* 	EXAMPLE USAGE
+
+	var settings struct {
+		Theme       string `db:"theme"`
+		PageSize    int    `db:"page_size"`
+		Newsletters bool   `db:"newsletters"`
+	}
+	err = drysql.QueryKeyValuesIntoStruct("SELECT name, value FROM user_settings WHERE user_id = ?", []interface{}{userID}, &settings)
+*/
+
+func (drysql DrySql) QueryKeyValuesIntoStruct(query string, inputs []interface{}, dest interface{}) error {
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrInvalidDestination
+	}
+	v = v.Elem()
+	structType := v.Type()
+
+	fields := make(map[string]int)
+	decoders := make(map[int]decodeFunc)
+	for i := 0; i < structType.NumField(); i++ {
+		if tag := parseTag(structType.Field(i)); tag.name != "" {
+			fields[drysql.columnMatchKey(tag.name)] = i
+			if decoder := drysql.fieldDecoder(structType.Field(i), tag); decoder != nil {
+				decoders[i] = decoder
+			}
+		}
+	}
+
+	return drysql.PreparedQuery(query, inputs, func(rows *sql.Rows) error {
+		var key string
+		var value interface{}
+		if err := rows.Scan(&key, &value); err != nil {
+			return err
+		}
+
+		index, ok := fields[drysql.columnMatchKey(key)]
+		if !ok {
+			if drysql.unknownKeyErrors {
+				return fmt.Errorf("drysql: %s has no field tagged %q", structType, key)
+			}
+			return nil
+		}
+
+		// scan the row again straight into the field so the value is converted to its type
+		var target interface{} = v.Field(index).Addr().Interface()
+		if decoder := decoders[index]; decoder != nil {
+			target = decodingScanner{field: v.Field(index), decode: decoder}
+		}
+
+		return rows.Scan(&key, target)
+	})
+}
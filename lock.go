@@ -0,0 +1,194 @@
+package drysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var ErrNotInTransaction = errors.New("drysql: locking reads must run in a transaction, see WithTx")
+
+var ErrLockingUnsupported = errors.New("drysql: the dialect does not support locking reads")
+
+// LockWait selects what a locking read does when a row it wants is already locked by another transaction
+type LockWait int
+
+const (
+	WaitForLock LockWait = iota // block until the lock is released
+	NoWait                      // fail straight away
+	SkipLocked                  // leave locked rows out of the result, e.g. for queue consumers grabbing the next free row
+)
+
+// ForUpdate returns the dialect's clause for locking the selected rows for update, to append to a hand written SELECT.
+// MySQL 8 and Postgres support every LockWait, SQLite has no row locks and returns ErrLockingUnsupported.
+func (drysql DrySql) ForUpdate(wait LockWait) (string, error) {
+	return drysql.lockingClause(" FOR UPDATE", wait)
+}
+
+// ForShare is ForUpdate for a shared lock, which blocks writers but not other readers taking a shared lock
+func (drysql DrySql) ForShare(wait LockWait) (string, error) {
+	return drysql.lockingClause(" FOR SHARE", wait)
+}
+
+func (drysql DrySql) lockingClause(clause string, wait LockWait) (string, error) {
+
+	if drysql.dialect == SQLite {
+		return "", ErrLockingUnsupported
+	}
+
+	switch wait {
+	case NoWait:
+		clause += " NOWAIT"
+	case SkipLocked:
+		clause += " SKIP LOCKED"
+	}
+
+	return clause, nil
+}
+
+// SelectForUpdate is SelectTableRows locking the selected rows until the transaction ends.
+// drysql must be bound to a transaction with WithTx, otherwise ErrNotInTransaction is returned as the lock would be
+// released as soon as the statement finished.
+
+/* 	EXAMPLE USAGE
+
+	tx, err := sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var accounts []Account
+	err = drysql.WithTx(tx).SelectForUpdate("accounts", &accounts, drysql.WaitForLock, drysql.Where("account_id IN (?, ?)", from, to))
+*/
+
+func (drysql DrySql) SelectForUpdate(tableName string, dest interface{}, wait LockWait, conditions ...Condition) error {
+	return drysql.selectLocked(" FOR UPDATE", tableName, dest, wait, conditions)
+}
+
+// SelectForShare is SelectForUpdate taking a shared lock, see ForShare
+func (drysql DrySql) SelectForShare(tableName string, dest interface{}, wait LockWait, conditions ...Condition) error {
+	return drysql.selectLocked(" FOR SHARE", tableName, dest, wait, conditions)
+}
+
+func (drysql DrySql) selectLocked(clause string, tableName string, dest interface{}, wait LockWait, conditions []Condition) error {
+
+	if _, ok := drysql.sqlImpl.(*sql.Tx); !ok {
+		return ErrNotInTransaction
+	}
+
+	lock, err := drysql.lockingClause(clause, wait)
+	if err != nil {
+		return err
+	}
+
+	tableName = drysql.tableName(tableName, dest)
+	drysql.warnOnTypeMismatch(tableName, conditions)
+	where, inputs := drysql.whereClause(conditions)
+	query := "SELECT " + drysql.SelectColumns(dest, "") + " FROM " + drysql.quote(tableName) + where
+	if drysql.maxRows > 0 {
+		// QueryIntoSlice would append its LIMIT after the locking clause
+		query += " LIMIT " + strconv.Itoa(drysql.maxRows+1)
+	}
+	query += lock
+
+	return drysql.QueryIntoSlice(drysql.rebind(query), inputs, dest)
+}
+
+// DequeueJob claims the next row of a jobs table whose statusColumn is 'pending', in one transaction: the row is selected
+// FOR UPDATE SKIP LOCKED so concurrent workers never claim the same job, its status is set to 'processing' and it is
+// scanned into the struct dest points to.  Jobs are claimed in order of dest's pk tagged fields, which identify the row.
+// found is false when no job is pending.  Any scope added WithScope also applies to the jobs considered.
+
+/* 	EXAMPLE USAGE
+
+	type Job struct {
+		JobID   int64  `db:"job_id,pk"`
+		Status  string `db:"status"`
+		Payload string `db:"payload"`
+	}
+
+	var job Job
+	found, err := drysql.DequeueJob("jobs", "status", &job)
+*/
+
+func (drysql DrySql) DequeueJob(tableName string, statusColumn string, dest interface{}) (found bool, err error) {
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return false, ErrInvalidDestination
+	}
+	jobType := v.Elem().Type()
+
+	var keys []int
+	var orderBy []OrderBy
+	for i := 0; i < jobType.NumField(); i++ {
+		if tag := parseTag(jobType.Field(i)); tag.name != "" && tag.has("pk") {
+			keys = append(keys, i)
+			orderBy = append(orderBy, Asc(tag.name))
+		}
+	}
+	if len(keys) == 0 {
+		return false, fmt.Errorf("drysql: %s has no pk tagged field identifying the job", jobType)
+	}
+
+	lock, err := drysql.lockingClause(" FOR UPDATE", SkipLocked)
+	if err != nil {
+		return false, err
+	}
+
+	beginner, ok := drysql.sqlImpl.(txBeginner)
+	if !ok {
+		return false, ErrTransactionsUnsupported
+	}
+	tx, err := beginner.BeginTx(context.Background(), nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	txDrySql := drysql.WithTx(tx).WithMaxRows(0)
+
+	tableName = drysql.tableName(tableName, dest)
+	where, inputs := drysql.whereClause([]Condition{Where(drysql.quote(statusColumn)+" = ?", "pending")})
+	query := "SELECT " + drysql.SelectColumns(dest, "") + " FROM " + drysql.quote(tableName) + where + drysql.OrderByClause(orderBy...) + " LIMIT 1" + lock
+
+	jobs := reflect.New(reflect.SliceOf(jobType))
+	if err = txDrySql.QueryIntoSlice(drysql.rebind(query), inputs, jobs.Interface()); err != nil {
+		return false, err
+	}
+	if jobs.Elem().Len() == 0 {
+		return false, tx.Commit()
+	}
+	job := jobs.Elem().Index(0)
+
+	var keyColumns []string
+	updateInputs := []interface{}{"processing"}
+	for _, key := range keys {
+		keyColumns = append(keyColumns, drysql.quote(parseTag(jobType.Field(key)).name)+" = ?")
+		value, err := driver.DefaultParameterConverter.ConvertValue(job.Field(key).Interface())
+		if err != nil {
+			return false, err
+		}
+		updateInputs = append(updateInputs, value)
+	}
+	update := "UPDATE " + drysql.quote(tableName) + " SET " + drysql.quote(statusColumn) + " = ? WHERE " + strings.Join(keyColumns, " AND ")
+	if _, err = txDrySql.PreparedExec(drysql.rebind(update), updateInputs); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+
+	if index, ok := taggedField(jobType, statusColumn); ok && job.Field(index).Kind() == reflect.String {
+		job.Field(index).SetString("processing")
+	}
+	v.Elem().Set(job)
+
+	return true, nil
+}
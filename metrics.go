@@ -0,0 +1,147 @@
+package drysql
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// metricsSampleSize is the number of most recent durations kept per fingerprint for the latency percentiles
+const metricsSampleSize = 1024
+
+// MetricsRegistry accumulates per QueryFingerprint query metrics of every DrySql recording into it with WithMetrics.
+// It is safe for concurrent use, and implements expvar.Var so it can be published with expvar.Publish.
+type MetricsRegistry struct {
+	mutex   sync.Mutex
+	queries map[string]*queryMetrics
+}
+
+type queryMetrics struct {
+	count         int64
+	errors        int64
+	totalDuration time.Duration
+	samples       []time.Duration // ring buffer of the most recent durations
+	next          int
+}
+
+// QueryMetrics are the metrics of one QueryFingerprint, P50 and P95 are taken over its most recent executions
+type QueryMetrics struct {
+	Fingerprint   string        `json:"fingerprint"`
+	Count         int64         `json:"count"`
+	Errors        int64         `json:"errors"`
+	TotalDuration time.Duration `json:"total_duration_ns"`
+	P50           time.Duration `json:"p50_ns"`
+	P95           time.Duration `json:"p95_ns"`
+}
+
+// MetricsSnapshot is a point in time copy of a MetricsRegistry, with Queries sorted by Fingerprint
+type MetricsSnapshot struct {
+	Queries []QueryMetrics `json:"queries"`
+}
+
+// NewMetricsRegistry returns an empty registry, see WithMetrics
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{queries: make(map[string]*queryMetrics)}
+}
+
+// WithMetrics returns a copy of drysql that records the count, duration and error of every query into registry, keyed by
+// QueryFingerprint.  Several DrySql values can share one registry, e.g. one per database handle of a service.
+
+/* 	EXAMPLE USAGE
+
+	metrics := drysql.NewMetricsRegistry()
+	expvar.Publish("drysql", metrics)
+	db := drysql.GetDrySqlImplementation(sqlDB).WithMetrics(metrics)
+
+	for _, query := range metrics.Snapshot().Queries {
+		queryLatency.WithLabelValues(query.Fingerprint, "p95").Set(query.P95.Seconds())
+	}
+*/
+
+func (drysql DrySql) WithMetrics(registry *MetricsRegistry) DrySql {
+	drysql.metrics = registry
+	return drysql
+}
+
+func (registry *MetricsRegistry) record(query string, duration time.Duration, err error) {
+
+	if registry == nil {
+		return
+	}
+
+	fingerprint := QueryFingerprint(query)
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	metrics := registry.queries[fingerprint]
+	if metrics == nil {
+		metrics = &queryMetrics{}
+		registry.queries[fingerprint] = metrics
+	}
+	metrics.count++
+	if err != nil {
+		metrics.errors++
+	}
+	metrics.totalDuration += duration
+	if len(metrics.samples) < metricsSampleSize {
+		metrics.samples = append(metrics.samples, duration)
+	} else {
+		metrics.samples[metrics.next] = duration
+		metrics.next = (metrics.next + 1) % metricsSampleSize
+	}
+}
+
+// Snapshot returns a copy of the metrics recorded so far
+func (registry *MetricsRegistry) Snapshot() MetricsSnapshot {
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	snapshot := MetricsSnapshot{Queries: make([]QueryMetrics, 0, len(registry.queries))}
+	for fingerprint, metrics := range registry.queries {
+		samples := append([]time.Duration(nil), metrics.samples...)
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		snapshot.Queries = append(snapshot.Queries, QueryMetrics{
+			Fingerprint:   fingerprint,
+			Count:         metrics.count,
+			Errors:        metrics.errors,
+			TotalDuration: metrics.totalDuration,
+			P50:           percentile(samples, 50),
+			P95:           percentile(samples, 95),
+		})
+	}
+	sort.Slice(snapshot.Queries, func(i, j int) bool { return snapshot.Queries[i].Fingerprint < snapshot.Queries[j].Fingerprint })
+
+	return snapshot
+}
+
+// Reset discards the metrics recorded so far, e.g. after each scrape when publishing deltas
+func (registry *MetricsRegistry) Reset() {
+	registry.mutex.Lock()
+	registry.queries = make(map[string]*queryMetrics)
+	registry.mutex.Unlock()
+}
+
+// String returns the Snapshot as JSON, implementing expvar.Var
+func (registry *MetricsRegistry) String() string {
+	encoded, err := json.Marshal(registry.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(encoded)
+}
+
+// percentile returns the nearest rank percentile p of the sorted samples
+func percentile(sorted []time.Duration, p int) time.Duration {
+
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
@@ -0,0 +1,75 @@
+package drysql
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMetricsRegistry(t *testing.T) {
+
+	fake, db := newFakeDB(t)
+	fake.setRows("SELECT first_name", []string{"first_name"}, []driver.Value{[]byte("Ann")})
+	fake.setRows("SELECT missing", []string{"first_name"})
+
+	metrics := NewMetricsRegistry()
+	db = db.WithMetrics(metrics)
+	for i := 0; i < 3; i++ {
+		db.QueryRowString("SELECT first_name FROM my_users WHERE user_id = ?", []interface{}{i})
+	}
+	db.QueryRowString("SELECT missing FROM my_users WHERE user_id = 7", nil)
+	db.QueryRowString("SELECT unknown FROM my_users", nil)
+
+	snapshot := metrics.Snapshot()
+	if len(snapshot.Queries) != 3 {
+		t.Fatalf("got %+v, want 3 fingerprints", snapshot.Queries)
+	}
+	want := map[string][2]int64{
+		"select first_name from my_users where user_id = ?": {3, 0},
+		"select missing from my_users where user_id = ?":    {1, 0}, // not found is not an error
+		"select unknown from my_users":                      {1, 1},
+	}
+	for _, query := range snapshot.Queries {
+		if counts := want[query.Fingerprint]; query.Count != counts[0] || query.Errors != counts[1] {
+			t.Errorf("%q counted %d queries and %d errors, want %d and %d", query.Fingerprint, query.Count, query.Errors, counts[0], counts[1])
+		}
+	}
+
+	var decoded MetricsSnapshot
+	if err := json.Unmarshal([]byte(metrics.String()), &decoded); err != nil || len(decoded.Queries) != 3 {
+		t.Errorf("String() = %s, %v", metrics.String(), err)
+	}
+
+	metrics.Reset()
+	if queries := metrics.Snapshot().Queries; len(queries) != 0 {
+		t.Errorf("got %+v after Reset", queries)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+
+	var samples []time.Duration
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, time.Duration(i))
+	}
+
+	tests := []struct {
+		samples []time.Duration
+		p       int
+		want    time.Duration
+	}{
+		{nil, 50, 0},
+		{samples[:1], 95, 1},
+		{samples, 50, 50},
+		{samples, 95, 95},
+		{samples[:3], 50, 2},
+		{samples[:3], 95, 3},
+	}
+
+	for _, test := range tests {
+		if got := percentile(test.samples, test.p); got != test.want {
+			t.Errorf("percentile(%d samples, %d) = %d, want %d", len(test.samples), test.p, got, test.want)
+		}
+	}
+}
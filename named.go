@@ -0,0 +1,98 @@
+package drysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PreparedQueryWithStruct is PreparedQuery with the inputs taken from the db tagged fields of argStruct, each referenced
+// in query by a :tag token, e.g. :user_id.  A field can be referenced more than once, tokens inside quotes and comments
+// are left alone, as are Postgres :: casts.  Values get the timefmt and bool formatting of their tags but no enum check
+// or encryption, as they are compared rather than written.
+
+/* 	EXAMPLE USAGE
+
+	filter := struct {
+		TeamID int64  `db:"team_id"`
+		Status string `db:"status"`
+	}{TeamID: 7, Status: "active"}
+
+	err = drysql.PreparedQueryWithStruct("SELECT user_id FROM my_users WHERE team_id = :team_id AND (status = :status OR :status = '')",
+		filter, func(rows *sql.Rows) error {
+			return nil
+		})
+*/
+
+func (drysql DrySql) PreparedQueryWithStruct(query string, argStruct interface{}, scanner func(rows *sql.Rows) error) error {
+
+	query, inputs, err := drysql.bindStruct(query, argStruct)
+	if err != nil {
+		return err
+	}
+
+	return drysql.PreparedQuery(query, inputs, scanner)
+}
+
+// bindStruct replaces the :tag tokens of query with placeholders for the dialect, returning the matching field values of argStruct
+func (drysql DrySql) bindStruct(query string, argStruct interface{}) (string, []interface{}, error) {
+
+	v := reflect.Indirect(reflect.ValueOf(argStruct))
+	if v.Kind() != reflect.Struct {
+		return "", nil, ErrInvalidDestination
+	}
+
+	var bound strings.Builder
+	var fields []insertField
+	for i := 0; i < len(query); i++ {
+		if end := drysql.literalEnd(query, i); end > i {
+			bound.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+		switch c := query[i]; {
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			bound.WriteString("::")
+			i++
+		case c == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			end := i + 1
+			for end < len(query) && (isNameStart(query[end]) || query[end] >= '0' && query[end] <= '9') {
+				end++
+			}
+			index, ok := taggedField(v.Type(), query[i+1:end])
+			if !ok {
+				return "", nil, fmt.Errorf("drysql: %s has no field tagged %q", v.Type(), query[i+1:end])
+			}
+			fields = append(fields, insertField{index: index, tag: parseTag(v.Type().Field(index))})
+			bound.WriteByte('?')
+			i = end - 1
+		default:
+			bound.WriteByte(c)
+		}
+	}
+
+	// arguments are compared rather than written, so enum checks and encryption don't apply
+	inputs := make([]interface{}, len(fields))
+	for i, field := range fields {
+		fieldValue := v.Field(field.index).Interface()
+		if fieldValue == Null {
+			continue
+		}
+		value, err := driver.DefaultParameterConverter.ConvertValue(fieldValue)
+		if err != nil {
+			return "", nil, err
+		}
+		if value != nil {
+			value = drysql.formatColumn(field.tag, value)
+		}
+		inputs[i] = value
+	}
+
+	return drysql.rebind(bound.String()), inputs, nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
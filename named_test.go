@@ -0,0 +1,49 @@
+package drysql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBindStruct(t *testing.T) {
+
+	filter := struct {
+		TeamID  int64     `db:"team_id"`
+		Status  string    `db:"status,enum=active|inactive"`
+		Secret  string    `db:"secret,encrypted"`
+		Active  bool      `db:"active,bool=Y/N"`
+		Day     time.Time `db:"day,timefmt=2006-01-02"`
+		Missing *string   `db:"missing"`
+	}{TeamID: 7, Active: true, Day: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    string
+		inputs  []interface{}
+	}{
+		{MySQL, "SELECT a FROM t WHERE team_id = :team_id AND (status = :status OR :status = '')",
+			"SELECT a FROM t WHERE team_id = ? AND (status = ? OR ? = '')", []interface{}{int64(7), "", ""}},
+		{Postgres, "SELECT a FROM t WHERE team_id = :team_id AND created::date = :day",
+			"SELECT a FROM t WHERE team_id = $1 AND created::date = $2", []interface{}{int64(7), "2024-03-01"}},
+		{MySQL, "SELECT ':team_id' FROM t -- :status\nWHERE active = :active /* :day */ AND x = :missing",
+			"SELECT ':team_id' FROM t -- :status\nWHERE active = ? /* :day */ AND x = ?", []interface{}{"Y", nil}},
+		{MySQL, "SELECT a FROM t WHERE secret = :secret", "SELECT a FROM t WHERE secret = ?", []interface{}{""}},
+	}
+
+	for _, test := range tests {
+		query, inputs, err := (DrySql{dialect: test.dialect}).bindStruct(test.query, filter)
+		if err != nil {
+			t.Errorf("bindStruct(%q) returned %v", test.query, err)
+			continue
+		}
+		if query != test.want || !reflect.DeepEqual(inputs, test.inputs) {
+			t.Errorf("bindStruct(%q) = %q %#v, want %q %#v", test.query, query, inputs, test.want, test.inputs)
+		}
+	}
+
+	if _, _, err := (DrySql{}).bindStruct("SELECT a FROM t WHERE b = :unknown", filter); err == nil {
+		t.Error("bindStruct with an unknown :token returned no error")
+	}
+}
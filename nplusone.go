@@ -0,0 +1,39 @@
+package drysql
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+type repeatedQueryDetector struct {
+	threshold int
+	mutex     sync.Mutex
+	counts    map[string]int
+}
+
+// WithNPlusOneDetection returns a copy of drysql that logs a warning through SqlLogger, with a stack trace of the call,
+// the first time queries sharing a QueryFingerprint run more than threshold times.  Counts are shared by the returned
+// DrySql and its copies, create one per request, e.g. in middleware, to detect N+1 access patterns within that request.
+func (drysql DrySql) WithNPlusOneDetection(threshold int) DrySql {
+	drysql.repeatedQueries = &repeatedQueryDetector{threshold: threshold, counts: make(map[string]int)}
+	return drysql
+}
+
+func (drysql DrySql) detectRepeatedQuery(query string) {
+
+	detector := drysql.repeatedQueries
+	if detector == nil {
+		return
+	}
+
+	fingerprint := QueryFingerprint(query)
+	detector.mutex.Lock()
+	detector.counts[fingerprint]++
+	count := detector.counts[fingerprint]
+	detector.mutex.Unlock()
+
+	if count == detector.threshold+1 {
+		logSqlWarning(query, fmt.Sprintf("possible N+1 query, %q ran more than %d times\n%s", fingerprint, detector.threshold, debug.Stack()))
+	}
+}
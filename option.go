@@ -0,0 +1,25 @@
+package drysql
+
+// Option overrides part of the configuration of a DrySql.  Every With method can be used as an Option through a
+// closure or method expression, e.g. drysql.DrySql.WithStrictUpdates, so a set of overrides can be built once and passed around.
+type Option func(drysql DrySql) DrySql
+
+// With returns a copy of drysql with options applied in order.  The receiver is left unchanged, so the overrides apply to
+// the calls made on the returned copy only, e.g. to lift the row limit or turn on EXPLAIN warnings for a single query.
+
+/* 	EXAMPLE USAGE
+
+	var oneOff []drysql.Option
+	oneOff = append(oneOff, drysql.DrySql.WithStrictUpdates, func(d drysql.DrySql) drysql.DrySql { return d.WithMaxRows(0) })
+
+	err = drysql.With(oneOff...).QueryIntoSlice("SELECT user_id, first_name FROM my_users", nil, &users)
+*/
+
+func (drysql DrySql) With(options ...Option) DrySql {
+
+	for _, option := range options {
+		drysql = option(drysql)
+	}
+
+	return drysql
+}
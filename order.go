@@ -0,0 +1,92 @@
+package drysql
+
+import (
+	"strings"
+)
+
+// NullsOrder places NULLs before or after the other values of an OrderBy column
+type NullsOrder int
+
+const (
+	NullsDefault NullsOrder = iota // the database's default, NULLs sort lowest in MySQL and SQLite, highest in Postgres
+	NullsFirst
+	NullsLast
+)
+
+// OrderBy is one ORDER BY column, build it with Asc or Desc, e.g. drysql.Desc("last_login").NullsLast()
+type OrderBy struct {
+	Column string
+	Desc   bool
+	Nulls  NullsOrder
+}
+
+func Asc(column string) OrderBy {
+	return OrderBy{Column: column}
+}
+
+func Desc(column string) OrderBy {
+	return OrderBy{Column: column, Desc: true}
+}
+
+func (order OrderBy) NullsFirst() OrderBy {
+	order.Nulls = NullsFirst
+	return order
+}
+
+func (order OrderBy) NullsLast() OrderBy {
+	order.Nulls = NullsLast
+	return order
+}
+
+// OrderByClause returns " ORDER BY ..." for orders, or "" when there are none.
+// Postgres and SQLite get NULLS FIRST / NULLS LAST, MySQL lacks them so NULLs are ordered with a leading IS NULL term,
+// e.g. Desc("last_login").NullsLast() is ORDER BY last_login IS NULL, last_login DESC.
+func (drysql DrySql) OrderByClause(orders ...OrderBy) string {
+
+	if len(orders) == 0 {
+		return ""
+	}
+
+	terms := make([]string, 0, len(orders))
+	for _, order := range orders {
+		column := drysql.quote(order.Column)
+		term := column
+		if order.Desc {
+			term += " DESC"
+		}
+
+		switch {
+		case order.Nulls == NullsDefault:
+		case drysql.dialect == MySQL && order.Nulls == NullsFirst:
+			terms = append(terms, column+" IS NULL DESC")
+		case drysql.dialect == MySQL:
+			terms = append(terms, column+" IS NULL")
+		case order.Nulls == NullsFirst:
+			term += " NULLS FIRST"
+		default:
+			term += " NULLS LAST"
+		}
+		terms = append(terms, term)
+	}
+
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// SelectTableRowsOrderBy is SelectTableRows with the rows sorted by orderBy, see OrderByClause
+
+/* 	EXAMPLE USAGE
+
+	var users []User
+	err = drysql.SelectTableRowsOrderBy("my_users", &users, []drysql.OrderBy{drysql.Desc("last_login").NullsLast(), drysql.Asc("user_id")},
+		drysql.Where("team_id = ?", teamID))
+*/
+
+func (drysql DrySql) SelectTableRowsOrderBy(tableName string, dest interface{}, orderBy []OrderBy, conditions ...Condition) error {
+
+	tableName = drysql.tableName(tableName, dest)
+	drysql.warnOnTypeMismatch(tableName, conditions)
+	where, inputs := drysql.whereClause(conditions)
+	query := "SELECT " + drysql.SelectColumns(dest, "") + " FROM " + drysql.quote(tableName) + where + drysql.OrderByClause(orderBy...)
+
+	return drysql.QueryIntoSlice(drysql.rebind(query), inputs, dest)
+}
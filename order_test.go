@@ -0,0 +1,27 @@
+package drysql
+
+import (
+	"testing"
+)
+
+func TestOrderByClause(t *testing.T) {
+
+	tests := []struct {
+		dialect Dialect
+		orders  []OrderBy
+		want    string
+	}{
+		{MySQL, nil, ""},
+		{MySQL, []OrderBy{Asc("user_id")}, " ORDER BY user_id"},
+		{MySQL, []OrderBy{Desc("last_login").NullsLast(), Asc("user_id")}, " ORDER BY last_login IS NULL, last_login DESC, user_id"},
+		{MySQL, []OrderBy{Asc("last_login").NullsFirst()}, " ORDER BY last_login IS NULL DESC, last_login"},
+		{Postgres, []OrderBy{Desc("last_login").NullsLast()}, " ORDER BY last_login DESC NULLS LAST"},
+		{SQLite, []OrderBy{Asc("last_login").NullsFirst(), Desc("user_id")}, " ORDER BY last_login NULLS FIRST, user_id DESC"},
+	}
+
+	for _, test := range tests {
+		if got := (DrySql{dialect: test.dialect}).OrderByClause(test.orders...); got != test.want {
+			t.Errorf("OrderByClause(%+v) = %q, want %q", test.orders, got, test.want)
+		}
+	}
+}
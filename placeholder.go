@@ -0,0 +1,139 @@
+package drysql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PlaceholderCount returns the number of parameters query expects, ignoring anything inside quotes, comments and Postgres $$ bodies.
+// Counts ? placeholders for MySQL and SQLite, and the highest $N for Postgres since $N can be referenced more than once.
+func (drysql DrySql) PlaceholderCount(query string) int {
+
+	count := 0
+	for i := 0; i < len(query); i++ {
+		if end := drysql.literalEnd(query, i); end > i {
+			i = end - 1
+			continue
+		}
+		switch c := query[i]; {
+		case c == '?' && drysql.dialect != Postgres:
+			count++
+		case c == '$' && drysql.dialect == Postgres:
+			end := i + 1
+			for end < len(query) && query[end] >= '0' && query[end] <= '9' {
+				end++
+			}
+			if n, err := strconv.Atoi(query[i+1 : end]); err == nil && n > count {
+				count = n
+			}
+			i = end - 1
+		}
+	}
+
+	return count
+}
+
+// literalEnd returns the index just past the quoted string or identifier, comment, or Postgres dollar quoted body
+// starting at query[start], or start when none starts there.  Every helper rewriting or inspecting SQL skips these
+// with it so they agree on what is a placeholder.
+func (drysql DrySql) literalEnd(query string, start int) int {
+
+	switch c := query[start]; {
+	case c == '\'' || c == '"' || c == '`':
+		if end := skipQuoted(query, start); end < len(query) {
+			return end + 1
+		}
+		return len(query)
+	case c == '-' && strings.HasPrefix(query[start:], "--"), c == '#' && drysql.dialect == MySQL:
+		if end := strings.IndexByte(query[start:], '\n'); end >= 0 {
+			return start + end
+		}
+		return len(query)
+	case c == '/' && strings.HasPrefix(query[start:], "/*"):
+		if end := strings.Index(query[start+2:], "*/"); end >= 0 {
+			return start + 2 + end + 2
+		}
+		return len(query)
+	case c == '$' && drysql.dialect == Postgres:
+		// $$ or $tag$, a $ followed by a digit is a placeholder
+		tagEnd := start + 1
+		for tagEnd < len(query) && (isNameStart(query[tagEnd]) || tagEnd > start+1 && query[tagEnd] >= '0' && query[tagEnd] <= '9') {
+			tagEnd++
+		}
+		if tagEnd < len(query) && query[tagEnd] == '$' {
+			delimiter := query[start : tagEnd+1]
+			if end := strings.Index(query[tagEnd+1:], delimiter); end >= 0 {
+				return tagEnd + 1 + end + len(delimiter)
+			}
+			return len(query)
+		}
+	}
+
+	return start
+}
+
+// skipQuoted returns the index of the quote closing the quoted string or identifier starting at start.
+// A doubled quote is an escaped quote, as is a backslash escape inside a single quoted string.
+func skipQuoted(query string, start int) int {
+
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote == '\'' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+			} else {
+				return i
+			}
+		}
+	}
+
+	return len(query)
+}
+
+// PlaceholderFunc renders the placeholder for the index'th parameter of a query, counting from 1
+type PlaceholderFunc func(index int) string
+
+// WithPlaceholderFunc returns a copy of drysql that renders the placeholders of the SQL it generates with placeholder,
+// for drivers whose syntax no Dialect covers, e.g. func(i int) string { return "@p" + strconv.Itoa(i) } for SQL Server.
+// Parameters are numbered in the order they appear in the generated query.
+func (drysql DrySql) WithPlaceholderFunc(placeholder PlaceholderFunc) DrySql {
+	drysql.placeholder = placeholder
+	return drysql
+}
+
+// rebind rewrites the ? placeholders of a generated query for the dialect, e.g. $1, $2 for Postgres,
+// or with the PlaceholderFunc when there is one
+func (drysql DrySql) rebind(query string) string {
+
+	placeholder := drysql.placeholder
+	if placeholder == nil && drysql.dialect == Postgres {
+		placeholder = func(index int) string { return "$" + strconv.Itoa(index) }
+	}
+	if placeholder == nil {
+		return query
+	}
+
+	var rebound strings.Builder
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if end := drysql.literalEnd(query, i); end > i {
+			rebound.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+		switch c := query[i]; c {
+		case '?':
+			n++
+			rebound.WriteString(placeholder(n))
+		default:
+			rebound.WriteByte(c)
+		}
+	}
+
+	return rebound.String()
+}
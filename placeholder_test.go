@@ -0,0 +1,55 @@
+package drysql
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRebind(t *testing.T) {
+
+	postgres := DrySql{dialect: Postgres}
+	tests := []struct {
+		drysql DrySql
+		query  string
+		want   string
+	}{
+		{postgres, "SELECT a FROM t WHERE b = ? AND c IN (?, ?)", "SELECT a FROM t WHERE b = $1 AND c IN ($2, $3)"},
+		{postgres, "SELECT '?', \"a?\" FROM t WHERE b = ?", "SELECT '?', \"a?\" FROM t WHERE b = $1"},
+		{postgres, "SELECT 'it''s ?' FROM t WHERE b = ?", "SELECT 'it''s ?' FROM t WHERE b = $1"},
+		{postgres, "SELECT a FROM t WHERE b = ? -- why?\nAND c = ?", "SELECT a FROM t WHERE b = $1 -- why?\nAND c = $2"},
+		{postgres, "SELECT a /* ? */ FROM t WHERE b = ?", "SELECT a /* ? */ FROM t WHERE b = $1"},
+		{postgres, "SELECT $$ ? $$, $tag$ ? $tag$ WHERE b = ?", "SELECT $$ ? $$, $tag$ ? $tag$ WHERE b = $1"},
+		{postgres, "SELECT a FROM t WHERE b = 'unterminated ?", "SELECT a FROM t WHERE b = 'unterminated ?"},
+		{DrySql{dialect: MySQL}, "SELECT a FROM t WHERE b = ?", "SELECT a FROM t WHERE b = ?"},
+		{DrySql{placeholder: func(i int) string { return "@p" + strconv.Itoa(i) }}, "SELECT a FROM t WHERE b = ? # ?\nAND c = ?", "SELECT a FROM t WHERE b = @p1 # ?\nAND c = @p2"},
+	}
+
+	for _, test := range tests {
+		if got := test.drysql.rebind(test.query); got != test.want {
+			t.Errorf("rebind(%q) = %q, want %q", test.query, got, test.want)
+		}
+	}
+}
+
+func TestPlaceholderCount(t *testing.T) {
+
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    int
+	}{
+		{MySQL, "SELECT a FROM t WHERE b = ? AND c = ?", 2},
+		{MySQL, "SELECT '?', `?` FROM t WHERE b = ? # ?\n-- ?\n/* ? */", 1},
+		{SQLite, "SELECT a FROM t WHERE b = ? AND c = \"?\"", 1},
+		{Postgres, "SELECT a FROM t WHERE b = $2 AND c = $1 OR d = $2", 2},
+		{Postgres, "SELECT $$ $5 $$, $fn$ $7 $fn$ FROM t WHERE b = $1", 1},
+		{Postgres, "SELECT '$3' FROM t -- $4\nWHERE b = $1", 1},
+		{Postgres, "SELECT a FROM t", 0},
+	}
+
+	for _, test := range tests {
+		if got := (DrySql{dialect: test.dialect}).PlaceholderCount(test.query); got != test.want {
+			t.Errorf("PlaceholderCount(%q) = %d, want %d", test.query, got, test.want)
+		}
+	}
+}
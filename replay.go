@@ -0,0 +1,103 @@
+package drysql
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// replayedQuery is one line of a workload written by WriteCapturedQueries
+type replayedQuery struct {
+	Query    string        `json:"query"`
+	Args     []interface{} `json:"args,omitempty"`
+	ArgCount int           `json:"arg_count"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// WriteCapturedQueries writes the queries recorded WithQueryCapture to w as JSON lines for Replay.
+// Arguments are redacted unless withArgs is true, only their number is kept and Replay binds NULL for each of them.
+// Captured argument values are written as JSON, so []byte arguments are replayed as base64 strings and times as RFC 3339 strings.
+
+/* 	EXAMPLE USAGE
+
+	db := drysql.GetDrySqlImplementation(sqlDB).WithQueryCapture()
+	runWorkload(db)
+
+	file, err := os.Create("workload.jsonl")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = db.WriteCapturedQueries(file, false)
+*/
+
+func (drysql DrySql) WriteCapturedQueries(w io.Writer, withArgs bool) error {
+
+	encoder := json.NewEncoder(w)
+	for _, captured := range drysql.CapturedQueries() {
+		query := replayedQuery{Query: captured.Query, ArgCount: len(captured.Args), Duration: captured.Duration}
+		if withArgs {
+			query.Args = captured.Args
+		}
+		if err := encoder.Encode(query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Replay runs the workload written by WriteCapturedQueries against drysql, at most queriesPerSecond queries a second
+// or as fast as possible when queriesPerSecond is 0.  SELECT queries are read to the end and their rows discarded,
+// everything else is executed.  Replay stops at the first error, returning the number of queries run before it.
+
+/* 	EXAMPLE USAGE
+
+	file, err := os.Open("workload.jsonl")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	replayed, err := drysql.GetDrySqlImplementation(stagingDB).Replay(file, 200)
+*/
+
+func (drysql DrySql) Replay(r io.Reader, queriesPerSecond float64) (replayed int, err error) {
+
+	var interval time.Duration
+	if queriesPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / queriesPerSecond)
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	next := time.Now()
+	for {
+		var query replayedQuery
+		if err = decoder.Decode(&query); err == io.EOF {
+			return replayed, nil
+		} else if err != nil {
+			return replayed, err
+		}
+
+		inputs := query.Args
+		if inputs == nil {
+			inputs = make([]interface{}, query.ArgCount)
+		}
+
+		if interval > 0 {
+			time.Sleep(time.Until(next))
+			next = next.Add(interval)
+		}
+
+		if drysql.isSelect(query.Query) {
+			err = drysql.PreparedQuery(query.Query, inputs, func(rows *sql.Rows) error { return nil })
+		} else {
+			_, err = drysql.PreparedExec(query.Query, inputs)
+		}
+		if err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+}
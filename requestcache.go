@@ -0,0 +1,210 @@
+package drysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+type requestCacheKey struct{}
+
+var lastDecoderID uint64
+
+// newDecoderID returns a new id for a DrySql whose column normalizer or encryptor was set
+func newDecoderID() uint64 {
+	return atomic.AddUint64(&lastDecoderID, 1)
+}
+
+// requestCache memoizes the reads of one request, keyed by the kind of read, the query and its args
+type requestCache struct {
+	mutex   sync.Mutex
+	entries map[string]requestCacheEntry
+}
+
+type requestCacheEntry struct {
+	values []reflect.Value // copies of the QueryRow outputs, or the QueryIntoSlice elements as a single slice
+	err    error           // ErrNotFound is cached like a result
+}
+
+// ContextWithRequestCache returns a copy of ctx carrying an empty cache for WithRequestCache, e.g. in middleware,
+// so the cache lives and dies with the request
+func ContextWithRequestCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestCacheKey{}, &requestCache{entries: make(map[string]requestCacheEntry)})
+}
+
+// WithRequestCache returns a copy of drysql whose QueryRow, and the helpers built on it, and QueryIntoSlice return the
+// result of the first identical SELECT and args run through the cache of ctx instead of querying again.  Every layer of
+// a request calling WithRequestCache with that request's ctx shares its cache.  Any PreparedExec or ExecWithoutPrepare
+// through the returned DrySql empties the cache so a read after a write sees the write, writes through other DrySql
+// values don't.  Reads in a transaction, e.g. by ReadSnapshot or WithTx, and locking reads such as SelectForUpdate
+// always query.  Cached values are shallow copies, a pointer in a scanned struct is shared between hits.
+// drysql is returned unchanged when ctx has no cache from ContextWithRequestCache.
+
+/* 	EXAMPLE USAGE
+
+	func middleware(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(drysql.ContextWithRequestCache(r.Context())))
+		})
+	}
+
+	func currentUser(ctx context.Context, userID int64) (User, error) {
+		var users []User
+		err := appDB.WithRequestCache(ctx).QueryIntoSlice("SELECT user_id, first_name FROM my_users WHERE user_id = ?", []interface{}{userID}, &users)
+		...
+	}
+*/
+
+func (drysql DrySql) WithRequestCache(ctx context.Context) DrySql {
+	drysql.requestCache, _ = ctx.Value(requestCacheKey{}).(*requestCache)
+	return drysql
+}
+
+// cacheKey returns the request cache key of a read, false when there is no cache, query is not a read only SELECT,
+// e.g. an INSERT ... RETURNING run through QueryRow, or an arg can't be converted to a driver value.
+// Reads in a transaction and locking reads are never cached, they must see the transaction's snapshot and take their locks.
+func (drysql DrySql) cacheKey(kind string, query string, inputs []interface{}) (string, bool) {
+
+	if drysql.requestCache == nil || !drysql.isSelect(query) {
+		return "", false
+	}
+	if _, inTx := drysql.sqlImpl.(*sql.Tx); inTx {
+		return "", false
+	}
+	if words, _, _ := drysql.statementWords(query); hasLockingClause(words) {
+		return "", false
+	}
+
+	values := make([]driver.Value, len(inputs))
+	for i, input := range inputs {
+		value, err := driver.DefaultParameterConverter.ConvertValue(input)
+		if err != nil {
+			return "", false
+		}
+		values[i] = value
+	}
+
+	// the settings changing how columns are decoded are part of the key, so copies configured differently don't share results
+	settings := fmt.Sprintf("%d/%v/%v/%v/%q/%q", drysql.decoderID, drysql.trimStrings, drysql.continueOnScanErrors, drysql.unknownKeyErrors, drysql.boolTrue, drysql.boolFalse)
+
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%#v", kind, settings, query, values), true
+}
+
+func (cache *requestCache) get(key string) (requestCacheEntry, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	entry, ok := cache.entries[key]
+	return entry, ok
+}
+
+func (cache *requestCache) set(key string, entry requestCacheEntry) {
+	cache.mutex.Lock()
+	cache.entries[key] = entry
+	cache.mutex.Unlock()
+}
+
+// clear empties the cache after a write
+func (cache *requestCache) clear() {
+	if cache == nil {
+		return
+	}
+	cache.mutex.Lock()
+	cache.entries = make(map[string]requestCacheEntry)
+	cache.mutex.Unlock()
+}
+
+// cachedQueryRow returns the cached result of QueryRow into outputs, false on a miss or when outputs don't match the
+// types cached for the query
+func (drysql DrySql) cachedQueryRow(key string, outputs []interface{}) (bool, error) {
+
+	entry, ok := drysql.requestCache.get(key)
+	if !ok {
+		return false, nil
+	}
+	if entry.err != nil {
+		return true, entry.err
+	}
+	if len(entry.values) != len(outputs) {
+		return false, nil
+	}
+	for i, output := range outputs {
+		v := reflect.ValueOf(output)
+		if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Type() != entry.values[i].Type() {
+			return false, nil
+		}
+	}
+
+	for i, output := range outputs {
+		reflect.ValueOf(output).Elem().Set(copyCachedValue(entry.values[i]))
+	}
+
+	return true, nil
+}
+
+// cacheQueryRow caches the outputs of a successful QueryRow, or its ErrNotFound
+func (drysql DrySql) cacheQueryRow(key string, outputs []interface{}, err error) {
+
+	if err == ErrNotFound {
+		drysql.requestCache.set(key, requestCacheEntry{err: err})
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	values := make([]reflect.Value, len(outputs))
+	for i, output := range outputs {
+		values[i] = copyCachedValue(reflect.ValueOf(output).Elem())
+	}
+	drysql.requestCache.set(key, requestCacheEntry{values: values})
+}
+
+// cachedSlice appends the cached elements of QueryIntoSlice to slice, false on a miss or when slice has another type
+func (drysql DrySql) cachedSlice(key string, slice reflect.Value) bool {
+
+	entry, ok := drysql.requestCache.get(key)
+	if !ok || len(entry.values) != 1 || entry.values[0].Type() != slice.Type() {
+		return false
+	}
+
+	cached := entry.values[0]
+	for i := 0; i < cached.Len(); i++ {
+		slice.Set(reflect.Append(slice, copyCachedValue(cached.Index(i))))
+	}
+
+	return true
+}
+
+// cacheSlice caches the elements QueryIntoSlice appended to slice after its first from elements
+func (drysql DrySql) cacheSlice(key string, slice reflect.Value, from int) {
+
+	cached := reflect.MakeSlice(slice.Type(), 0, slice.Len()-from)
+	for i := from; i < slice.Len(); i++ {
+		cached = reflect.Append(cached, copyCachedValue(slice.Index(i)))
+	}
+	drysql.requestCache.set(key, requestCacheEntry{values: []reflect.Value{cached}})
+}
+
+// copyCachedValue returns a copy of v that the caller can modify without changing the cache, copying the struct a
+// pointer points to and the contents of a []byte
+func copyCachedValue(v reflect.Value) reflect.Value {
+
+	switch {
+	case v.Kind() == reflect.Ptr && !v.IsNil():
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(copyCachedValue(v.Elem()))
+		return copied
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 && !v.IsNil():
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		return copied
+	}
+
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	return copied
+}
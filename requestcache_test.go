@@ -0,0 +1,141 @@
+package drysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestRequestCache(t *testing.T) {
+
+	type user struct {
+		UserID    int64  `db:"user_id"`
+		FirstName string `db:"first_name"`
+	}
+
+	fake, appDB := newFakeDB(t)
+	fake.setRows("SELECT user_id, first_name", []string{"user_id", "first_name"}, []driver.Value{int64(1), []byte("Ann")})
+	fake.setRows("SELECT first_name", []string{"first_name"}, []driver.Value{[]byte("Ann")})
+	fake.setRows("SELECT missing", []string{"first_name"})
+
+	ctx := ContextWithRequestCache(context.Background())
+	for i := 0; i < 2; i++ {
+		db := appDB.WithRequestCache(ctx)
+
+		var users []*user
+		if err := db.QueryIntoSlice("SELECT user_id, first_name FROM my_users WHERE user_id = ?", []interface{}{1}, &users); err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != 1 || users[0].FirstName != "Ann" {
+			t.Fatalf("got %+v", users)
+		}
+		users[0].FirstName = "changed by the caller"
+
+		if name, err := db.QueryRowString("SELECT first_name FROM my_users WHERE user_id = ?", []interface{}{int32(1)}); err != nil || name != "Ann" {
+			t.Fatalf("QueryRowString = %q, %v", name, err)
+		}
+		if _, err := db.QueryRowString("SELECT missing FROM my_users", nil); err != ErrNotFound {
+			t.Fatalf("QueryRowString = %v, want ErrNotFound", err)
+		}
+	}
+	if got := len(fake.statements()); got != 3 {
+		t.Errorf("ran %d statements, want each read once: %q", got, fake.statements())
+	}
+
+	// a write empties the cache, a DrySql without the request's cache always queries
+	appDB.WithRequestCache(ctx).PreparedExec("UPDATE my_users SET first_name = ?", []interface{}{"Bo"})
+	appDB.WithRequestCache(ctx).QueryRowString("SELECT first_name FROM my_users WHERE user_id = ?", []interface{}{1})
+	appDB.WithRequestCache(context.Background()).QueryRowString("SELECT first_name FROM my_users WHERE user_id = ?", []interface{}{1})
+	if got := len(fake.statements()); got != 6 {
+		t.Errorf("ran %d statements, want 6: %q", got, fake.statements())
+	}
+}
+
+func TestRequestCacheSkipsWrites(t *testing.T) {
+
+	type user struct {
+		UserID    int64  `db:"user_id,pk,auto"`
+		FirstName string `db:"first_name"`
+	}
+
+	fake, appDB := newFakeDB(t)
+	fake.setRows("INSERT", []string{"user_id"}, []driver.Value{int64(9)})
+
+	db := appDB.WithDialect(Postgres).WithRequestCache(ContextWithRequestCache(context.Background()))
+	for i := 0; i < 2; i++ {
+		if err := db.InsertTableRowFromStruct("my_users", &user{FirstName: "Ann"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(fake.statements()); got != 2 {
+		t.Errorf("ran %q, want both inserts", fake.statements())
+	}
+}
+
+func TestRequestCacheSkipsTransactionsAndLockingReads(t *testing.T) {
+
+	fake, appDB := newFakeDB(t)
+	fake.setRows("SELECT", []string{"user_id"}, []driver.Value{int64(1)})
+	db := appDB.WithRequestCache(ContextWithRequestCache(context.Background()))
+
+	read := func(tx DrySql) error {
+		_, err := tx.QueryRowInt("SELECT user_id FROM my_users", nil)
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := read(db); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.ReadSnapshot(context.Background(), sql.LevelDefault, read); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.QueryRowInt("SELECT user_id FROM my_users FOR UPDATE", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the plain read is cached once, every transaction and locking read runs
+	want := []string{
+		"SELECT user_id FROM my_users []", "BEGIN isolation=0 readonly=true", "SELECT user_id FROM my_users []", "COMMIT", "SELECT user_id FROM my_users FOR UPDATE []",
+		"BEGIN isolation=0 readonly=true", "SELECT user_id FROM my_users []", "COMMIT", "SELECT user_id FROM my_users FOR UPDATE []",
+	}
+	if got := fake.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+}
+
+func TestRequestCacheKeyIncludesDecodeSettings(t *testing.T) {
+
+	fake, appDB := newFakeDB(t)
+	fake.setRows("SELECT", []string{"code"}, []driver.Value{[]byte("ab  ")})
+	ctx := ContextWithRequestCache(context.Background())
+
+	query := "SELECT code FROM codes"
+	normalizer := func(column string) string { return column }
+	tests := []struct {
+		db   DrySql
+		want string
+	}{
+		{appDB.WithRequestCache(ctx), "ab  "},
+		{appDB.WithTrimStrings().WithRequestCache(ctx), "ab"},
+		{appDB.WithRequestCache(ctx), "ab  "},
+		{appDB.WithColumnNormalizer(normalizer).WithRequestCache(ctx), "ab  "},
+	}
+
+	for _, test := range tests {
+		var codes []struct {
+			Code string `db:"code"`
+		}
+		if err := test.db.QueryIntoSlice(query, nil, &codes); err != nil {
+			t.Fatal(err)
+		}
+		if len(codes) != 1 || codes[0].Code != test.want {
+			t.Errorf("got %+v, want %q", codes, test.want)
+		}
+	}
+	if got := len(fake.statements()); got != 3 {
+		t.Errorf("ran %q, want one query per distinct configuration", fake.statements())
+	}
+}
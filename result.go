@@ -0,0 +1,60 @@
+package drysql
+
+import (
+	"database/sql"
+)
+
+// ExecResult holds the sql.Result and error of an exec so the result can be inspected without checking the error first
+type ExecResult struct {
+	Result sql.Result
+	Err    error
+}
+
+// NewExecResult wraps the return values of PreparedExec, e.g. drysql.NewExecResult(d.PreparedExec(query, inputs))
+func NewExecResult(result sql.Result, err error) ExecResult {
+	return ExecResult{Result: result, Err: err}
+}
+
+// PreparedExecResult is PreparedExec returning an ExecResult, e.g. d.PreparedExecResult(query, inputs).RowsAffectedOr(0)
+func (drysql DrySql) PreparedExecResult(query string, inputs []interface{}) ExecResult {
+	return NewExecResult(drysql.PreparedExec(query, inputs))
+}
+
+// RowsAffected returns the exec error if there was one, otherwise the result's RowsAffected
+func (result ExecResult) RowsAffected() (int64, error) {
+	if result.Err != nil {
+		return 0, result.Err
+	}
+	return result.Result.RowsAffected()
+}
+
+// LastInsertId returns the exec error if there was one, otherwise the result's LastInsertId
+func (result ExecResult) LastInsertId() (int64, error) {
+	if result.Err != nil {
+		return 0, result.Err
+	}
+	return result.Result.LastInsertId()
+}
+
+// MustRowsAffected returns the number of rows affected and panics on any error
+func (result ExecResult) MustRowsAffected() int64 {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		panic(err)
+	}
+	return rowsAffected
+}
+
+// RowsAffectedOr returns the number of rows affected, or defaultValue on any error
+func (result ExecResult) RowsAffectedOr(defaultValue int64) int64 {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return defaultValue
+	}
+	return rowsAffected
+}
+
+// Inserted reports whether the exec succeeded and affected at least one row
+func (result ExecResult) Inserted() bool {
+	return result.RowsAffectedOr(0) > 0
+}
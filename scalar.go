@@ -0,0 +1,90 @@
+package drysql
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// QueryRowInt scans the single column of the first row returned by query, returning 0 when the value is NULL,
+// e.g. SUM over an empty set.  ErrNotFound is still returned when the query returns no rows.
+func (drysql DrySql) QueryRowInt(query string, inputs []interface{}) (int64, error) {
+	var value sql.NullInt64
+	err := drysql.QueryRow(query, inputs, []interface{}{&value})
+	return value.Int64, err
+}
+
+// QueryRowFloat is QueryRowInt for float columns
+func (drysql DrySql) QueryRowFloat(query string, inputs []interface{}) (float64, error) {
+	var value sql.NullFloat64
+	err := drysql.QueryRow(query, inputs, []interface{}{&value})
+	return value.Float64, err
+}
+
+// QueryRowString is QueryRowInt for string columns, returning "" when the value is NULL
+func (drysql DrySql) QueryRowString(query string, inputs []interface{}) (string, error) {
+	var value sql.NullString
+	err := drysql.QueryRow(query, inputs, []interface{}{&value})
+	return value.String, err
+}
+
+// QueryRowBool is QueryRowInt for boolean columns, returning false when the value is NULL
+func (drysql DrySql) QueryRowBool(query string, inputs []interface{}) (bool, error) {
+	var value sql.NullBool
+	err := drysql.QueryRow(query, inputs, []interface{}{&value})
+	return value.Bool, err
+}
+
+// QueryScalar scans the first column of the first row returned by query into dest, which can be a pointer to any type
+// database/sql can scan into, including sql.Scanner implementations.  When the value is NULL dest is set to its zero
+// value and found is false, which is not an error.  Like the other single row helpers it returns ErrNotFound when there are no rows.
+
+/* 	EXAMPLE USAGE
+
+	var total float64
+	found, err := drysql.QueryScalar("SELECT SUM(amount) FROM payments WHERE user_id = ?", []interface{}{userID}, &total)
+	// found is false for a user without payments, SUM over no rows is NULL
+*/
+
+func (drysql DrySql) QueryScalar(query string, inputs []interface{}, dest interface{}) (found bool, err error) {
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return false, ErrInvalidDestination
+	}
+	v.Elem().Set(reflect.Zero(v.Elem().Type()))
+
+	stmtOut, rows, err := drysql.preparedRows(query, inputs)
+	if err != nil {
+		return false, err
+	}
+	defer stmtOut.Close()
+	defer rows.Close()
+
+	if err = firstRow(rows); err != nil {
+		return false, err
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return false, err
+	}
+
+	// scan once to check for NULL, then again into dest so the usual database/sql conversions apply
+	targets := make([]interface{}, len(columns))
+	for i := range targets {
+		targets[i] = new(interface{})
+	}
+	if err = rows.Scan(targets...); err != nil {
+		return false, err
+	}
+	if *(targets[0].(*interface{})) == nil {
+		return false, rows.Close()
+	}
+
+	targets[0] = dest
+	if err = rows.Scan(targets...); err != nil {
+		return false, err
+	}
+
+	return true, rows.Close()
+}
@@ -0,0 +1,33 @@
+package drysql
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestQueryScalar(t *testing.T) {
+
+	fake, db := newFakeDB(t)
+	fake.setRows("SELECT SUM", []string{"total"}, []driver.Value{[]byte("12.5")})
+	fake.setRows("SELECT NULL", []string{"total"}, []driver.Value{nil})
+	fake.setRows("SELECT NONE", []string{"total"})
+
+	tests := []struct {
+		query string
+		found bool
+		total float64
+		err   error
+	}{
+		{"SELECT SUM(amount) FROM payments", true, 12.5, nil},
+		{"SELECT NULL", false, 0, nil},
+		{"SELECT NONE", false, 0, ErrNotFound},
+	}
+
+	for _, test := range tests {
+		total := -1.0
+		found, err := db.QueryScalar(test.query, nil, &total)
+		if found != test.found || total != test.total || err != test.err {
+			t.Errorf("QueryScalar(%q) = %v, %v with %v, want %v, %v with %v", test.query, found, err, total, test.found, test.err, test.total)
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package drysql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidDestination = errors.New("drysql: invalid scan destination")
+
+var ErrResultTooLarge = errors.New("drysql: query returned more rows than the maximum allowed")
+
+var ErrUnknownMappedField = errors.New("drysql: field mapping names a field the destination struct does not have")
+
+// WithMaxRows returns a copy of drysql whose QueryIntoSlice and SelectTableRows return ErrResultTooLarge
+// when a query returns more than maxRows rows, guarding against accidentally loading a whole table.
+// LIMIT maxRows+1 is appended to a SELECT without a LIMIT, locking clause or semicolon of its own, other statements
+// such as a CALL are only capped by counting the rows read.  Zero removes the maximum,
+// so WithMaxRows can also override the instance default for a single call.
+func (drysql DrySql) WithMaxRows(maxRows int) DrySql {
+	drysql.maxRows = maxRows
+	return drysql
+}
+
+// WithContinueOnScanError returns a copy of drysql whose QueryIntoSlice and QueryIntoGroups skip rows that fail to scan,
+// e.g. a NULL in a non nullable field, instead of stopping at the first one.  Every other row is collected into dest
+// and the skipped rows are returned as ScanErrors once the result set is exhausted.
+func (drysql DrySql) WithContinueOnScanError() DrySql {
+	drysql.continueOnScanErrors = true
+	return drysql
+}
+
+// RowScanError is the error scanning one row, Row is its zero based position in the result set
+type RowScanError struct {
+	Row int
+	Err error
+}
+
+func (err RowScanError) Error() string {
+	return fmt.Sprintf("drysql: scanning row %d: %v", err.Row, err.Err)
+}
+
+func (err RowScanError) Unwrap() error {
+	return err.Err
+}
+
+// ScanErrors lists the rows skipped by a query run WithContinueOnScanError
+type ScanErrors []RowScanError
+
+func (errs ScanErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more rows)", errs[0], len(errs)-1)
+}
+
+// skipScanError records err against row when continuing on scan errors, returning the error to stop the query with otherwise
+func (drysql DrySql) skipScanError(scanErrors *ScanErrors, row int, err error) error {
+	if !drysql.continueOnScanErrors {
+		return err
+	}
+	*scanErrors = append(*scanErrors, RowScanError{Row: row, Err: err})
+	return nil
+}
+
+// structScanner maps the columns of a result set onto the db tagged fields of a struct type.
+// It is built once per query from rows.Columns() and reused for every row.
+type structScanner struct {
+	columns  []string
+	fields   []int        // struct field index for each column, -1 when no field is tagged with that column
+	decoders []decodeFunc // decoder for each column whose field has tag options applied on read, nil for plain fields
+	rawJSON  int          // index of the `db:",rawjson"` field receiving the whole row as a JSON object, -1 when there is none
+}
+
+func (drysql DrySql) newStructScanner(columns []string, structType reflect.Type) *structScanner {
+
+	scanner := &structScanner{
+		columns:  columns,
+		fields:   make([]int, len(columns)),
+		decoders: make([]decodeFunc, len(columns)),
+		rawJSON:  -1,
+	}
+
+	tagged := make(map[string]int)
+	decoders := make(map[int]decodeFunc)
+	for i := 0; i < structType.NumField(); i++ {
+		tag := parseTag(structType.Field(i))
+		if tag.has("rawjson") {
+			scanner.rawJSON = i
+		} else if tag.name != "" {
+			tagged[drysql.columnMatchKey(tag.name)] = i
+			if decoder := drysql.fieldDecoder(structType.Field(i), tag); decoder != nil {
+				decoders[i] = decoder
+			}
+		}
+	}
+
+	for i, column := range columns {
+		if index, ok := tagged[drysql.columnMatchKey(column)]; ok {
+			scanner.fields[i] = index
+			scanner.decoders[i] = decoders[index]
+		} else {
+			scanner.fields[i] = -1
+		}
+	}
+
+	return scanner
+}
+
+// WithColumnNormalizer returns a copy of drysql that applies normalize to both result column names and db tags
+// before matching them in the scan helpers, e.g. WithColumnNormalizer(drysql.SnakeCase) matches a `db:"firstName"` tag to a first_name column.
+// Matching is always case insensitive.
+func (drysql DrySql) WithColumnNormalizer(normalize func(string) string) DrySql {
+	drysql.normalizeColumn = normalize
+	drysql.decoderID = newDecoderID()
+	return drysql
+}
+
+func (drysql DrySql) columnMatchKey(column string) string {
+	if drysql.normalizeColumn != nil {
+		column = drysql.normalizeColumn(column)
+	}
+	return strings.ToLower(column)
+}
+
+// SnakeCase converts camelCase and PascalCase names to snake_case, e.g. UserID -> user_id and firstName -> first_name.
+// Names that are already snake_case are returned unchanged.
+func SnakeCase(name string) string {
+
+	runes := []rune(name)
+	var snake strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' {
+				previousLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if previousLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+					snake.WriteRune('_')
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		snake.WriteRune(r)
+	}
+
+	return snake.String()
+}
+
+// taggedField returns the index of the struct field tagged with column
+func taggedField(structType reflect.Type, column string) (int, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		if strings.EqualFold(parseTag(structType.Field(i)).name, column) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// scan reads the current row into dest, which must be an addressable struct value
+func (scanner *structScanner) scan(rows *sql.Rows, dest reflect.Value) error {
+
+	targets := make([]interface{}, len(scanner.fields))
+	for i, index := range scanner.fields {
+		if index < 0 {
+			targets[i] = new(interface{})
+		} else if scanner.decoders[i] != nil {
+			targets[i] = decodingScanner{field: dest.Field(index), decode: scanner.decoders[i]}
+		} else {
+			targets[i] = dest.Field(index).Addr().Interface()
+		}
+	}
+
+	if err := rows.Scan(targets...); err != nil {
+		return err
+	}
+
+	if scanner.rawJSON >= 0 {
+		return scanner.scanRawJSON(rows, dest.Field(scanner.rawJSON))
+	}
+
+	return nil
+}
+
+// scanRawJSON scans the current row a second time and stores it in field as a JSON object keyed by column name
+func (scanner *structScanner) scanRawJSON(rows *sql.Rows, field reflect.Value) error {
+
+	values := make([]interface{}, len(scanner.columns))
+	targets := make([]interface{}, len(scanner.columns))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return err
+	}
+
+	object := make(map[string]interface{}, len(scanner.columns))
+	for i, column := range scanner.columns {
+		if bytes, ok := values[i].([]byte); ok {
+			// most drivers return text columns as []byte, which would otherwise be encoded as base64
+			object[column] = string(bytes)
+		} else {
+			object[column] = values[i]
+		}
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	if field.Kind() != reflect.Slice || field.Type().Elem().Kind() != reflect.Uint8 {
+		return ErrInvalidDestination
+	}
+	field.SetBytes(data)
+
+	return nil
+}
+
+// sliceDestination validates that dest is a *[]T or *[]*T of a struct type T
+func sliceDestination(dest interface{}) (slice reflect.Value, structType reflect.Type, isPtr bool, err error) {
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return slice, nil, false, ErrInvalidDestination
+	}
+
+	slice = v.Elem()
+	structType = slice.Type().Elem()
+	if structType.Kind() == reflect.Ptr {
+		isPtr = true
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return slice, nil, false, ErrInvalidDestination
+	}
+
+	return slice, structType, isPtr, nil
+}
+
+// QueryIntoSlice runs a prepared query and appends one struct per row to the slice dest points to.
+// dest can be a *[]User or a *[]*User, fields are matched to columns by their db tag and columns without a matching field are ignored.
+// A []byte or json.RawMessage field tagged `db:",rawjson"` also receives every column of the row as a JSON object.
+// On error dest keeps the rows appended before it, see WithContinueOnScanError to skip rows that fail to scan.
+
+/* 	EXAMPLE USAGE
+
+	var users []*User
+	err = drysql.QueryIntoSlice("SELECT user_id, first_name FROM my_users WHERE last_name = ?", []interface{}{"Smith"}, &users)
+*/
+
+func (drysql DrySql) QueryIntoSlice(query string, inputs []interface{}, dest interface{}) error {
+
+	slice, _, _, err := sliceDestination(dest)
+	if err != nil {
+		return err
+	}
+
+	// the row cap is part of the key as it changes the result
+	key, cacheable := drysql.cacheKey("slice/"+strconv.Itoa(drysql.maxRows), query, inputs)
+	if cacheable && drysql.cachedSlice(key, slice) {
+		return nil
+	}
+	from := slice.Len()
+
+	err = drysql.queryIntoSlice(query, inputs, dest, func(columns []string, structType reflect.Type) (*structScanner, error) {
+		return drysql.newStructScanner(columns, structType), nil
+	})
+	if cacheable && err == nil {
+		drysql.cacheSlice(key, slice, from)
+	}
+
+	return err
+}
+
+// canAppendLimit reports whether a LIMIT can be added to the end of query, a SELECT or WITH that doesn't already end
+// in a LIMIT, FETCH, locking clause or semicolon
+func (drysql DrySql) canAppendLimit(query string) bool {
+
+	words, terminated, _ := drysql.statementWords(query)
+	if terminated || len(words) == 0 || words[0] != "SELECT" && words[0] != "WITH" {
+		return false
+	}
+	if hasLockingClause(words) {
+		return false
+	}
+	for _, word := range words {
+		if word == "LIMIT" || word == "FETCH" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// queryIntoSlice is QueryIntoSlice with the column to field mapping built by newScanner from the first row's columns
+func (drysql DrySql) queryIntoSlice(query string, inputs []interface{}, dest interface{}, newScanner func(columns []string, structType reflect.Type) (*structScanner, error)) error {
+
+	slice, structType, isPtr, err := sliceDestination(dest)
+	if err != nil {
+		return err
+	}
+
+	if drysql.maxRows > 0 && drysql.canAppendLimit(query) {
+		query += " LIMIT " + strconv.Itoa(drysql.maxRows+1)
+	}
+
+	drysql.warnOnFullScan(query, inputs)
+
+	var scanner *structScanner
+	var scanErrors ScanErrors
+	scanned := 0
+	err = drysql.PreparedQuery(query, inputs, func(rows *sql.Rows) error {
+		if scanner == nil {
+			columns, err := rows.Columns()
+			if err != nil {
+				return err
+			}
+			if scanner, err = newScanner(columns, structType); err != nil {
+				return err
+			}
+		}
+
+		if scanned++; drysql.maxRows > 0 && scanned > drysql.maxRows {
+			return ErrResultTooLarge
+		}
+
+		elem := reflect.New(structType)
+		if err := scanner.scan(rows, elem.Elem()); err != nil {
+			return drysql.skipScanError(&scanErrors, scanned-1, err)
+		}
+
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+		return nil
+	})
+
+	if err == nil && len(scanErrors) > 0 {
+		return scanErrors
+	}
+	return err
+}
+
+// QueryIntoSliceByPosition is QueryIntoSlice for result sets whose column names can't be relied on, e.g. the unnamed
+// columns of a stored procedure.  fieldMap names the field receiving each column in column order, by db tag or Go field name,
+// and an empty or "-" entry skips that column, as do any columns past the end of fieldMap.
+
+/* 	EXAMPLE USAGE
+
+	var users []User
+	err = drysql.QueryIntoSliceByPosition("CALL active_users(?)", []interface{}{since}, []string{"user_id", "-", "FirstName"}, &users)
+*/
+
+func (drysql DrySql) QueryIntoSliceByPosition(query string, inputs []interface{}, fieldMap []string, dest interface{}) error {
+	return drysql.queryIntoSlice(query, inputs, dest, func(columns []string, structType reflect.Type) (*structScanner, error) {
+		return drysql.newPositionalScanner(columns, structType, fieldMap)
+	})
+}
+
+// newPositionalScanner maps columns onto the fields named by fieldMap in column order rather than by column name
+func (drysql DrySql) newPositionalScanner(columns []string, structType reflect.Type, fieldMap []string) (*structScanner, error) {
+
+	scanner := &structScanner{
+		columns:  columns,
+		fields:   make([]int, len(columns)),
+		decoders: make([]decodeFunc, len(columns)),
+		rawJSON:  -1,
+	}
+
+	for i := range columns {
+		scanner.fields[i] = -1
+		if i >= len(fieldMap) || fieldMap[i] == "" || fieldMap[i] == "-" {
+			continue
+		}
+
+		index, ok := mappedField(structType, fieldMap[i])
+		if !ok {
+			return nil, ErrUnknownMappedField
+		}
+		scanner.fields[i] = index
+		field := structType.Field(index)
+		scanner.decoders[i] = drysql.fieldDecoder(field, parseTag(field))
+	}
+
+	for i := 0; i < structType.NumField(); i++ {
+		if parseTag(structType.Field(i)).has("rawjson") {
+			scanner.rawJSON = i
+		}
+	}
+
+	return scanner, nil
+}
+
+// mappedField returns the index of the exported struct field with the given db tag or, failing that, Go field name
+func mappedField(structType reflect.Type, name string) (int, bool) {
+
+	if index, ok := taggedField(structType, name); ok {
+		return index, true
+	}
+	if field, ok := structType.FieldByName(name); ok && len(field.Index) == 1 && field.PkgPath == "" {
+		return field.Index[0], true
+	}
+
+	return -1, false
+}
+
+// PreparedQueryPooled scans each row into a struct pointer obtained from newFn and passes it to fn.
+// newFn is typically a sync.Pool's Get, drysql never keeps a reference to the struct after fn returns,
+// so fn can hand it back to the pool once it is done with it.  The struct is zeroed before each scan.
+
+/* 	EXAMPLE USAGE
+
+	pool := sync.Pool{New: func() interface{} { return new(User) }}
+	err = drysql.PreparedQueryPooled("SELECT user_id, first_name FROM my_users", nil, pool.Get, func(row interface{}) error {
+		defer pool.Put(row)
+		return process(row.(*User))
+	})
+*/
+
+func (drysql DrySql) PreparedQueryPooled(query string, inputs []interface{}, newFn func() interface{}, fn func(interface{}) error) error {
+
+	var scanner *structScanner
+	var structType reflect.Type
+	return drysql.PreparedQuery(query, inputs, func(rows *sql.Rows) error {
+		dest := newFn()
+		v := reflect.ValueOf(dest)
+		if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return ErrInvalidDestination
+		}
+
+		if scanner == nil {
+			columns, err := rows.Columns()
+			if err != nil {
+				return err
+			}
+			structType = v.Elem().Type()
+			scanner = drysql.newStructScanner(columns, structType)
+		} else if v.Elem().Type() != structType {
+			return ErrInvalidDestination
+		}
+
+		v.Elem().Set(reflect.Zero(structType))
+		if err := scanner.scan(rows, v.Elem()); err != nil {
+			return err
+		}
+
+		return fn(dest)
+	})
+}
@@ -0,0 +1,142 @@
+package drysql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type scanUser struct {
+	UserID    int64   `db:"user_id"`
+	FirstName string  `db:"first_name"`
+	Nickname  *string `db:"nickname"`
+}
+
+func TestQueryIntoSlice(t *testing.T) {
+
+	nickname := "al"
+	want := []scanUser{{UserID: 1, FirstName: "Alice", Nickname: &nickname}, {UserID: 2, FirstName: "Bob"}}
+
+	tests := []struct {
+		name string
+		dest interface{}
+		got  func(dest interface{}) []scanUser
+	}{
+		{"structs", &[]scanUser{}, func(dest interface{}) []scanUser {
+			return *dest.(*[]scanUser)
+		}},
+		{"pointers", &[]*scanUser{}, func(dest interface{}) []scanUser {
+			var users []scanUser
+			for _, user := range *dest.(*[]*scanUser) {
+				users = append(users, *user)
+			}
+			return users
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake, db := newFakeDB(t)
+			fake.setRows("SELECT", []string{"user_id", "first_name", "nickname"},
+				[]driver.Value{int64(1), []byte("Alice"), []byte("al")},
+				[]driver.Value{int64(2), []byte("Bob"), nil})
+
+			if err := db.QueryIntoSlice("SELECT user_id, first_name, nickname FROM my_users", nil, test.dest); err != nil {
+				t.Fatal(err)
+			}
+			if got := test.got(test.dest); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestQueryIntoSliceInvalidDestination(t *testing.T) {
+
+	_, db := newFakeDB(t)
+	for _, dest := range []interface{}{nil, []scanUser{}, &scanUser{}, (*[]scanUser)(nil)} {
+		if err := db.QueryIntoSlice("SELECT user_id FROM my_users", nil, dest); err != ErrInvalidDestination {
+			t.Errorf("QueryIntoSlice into %T returned %v, want ErrInvalidDestination", dest, err)
+		}
+	}
+}
+
+func TestUnexportedTaggedFieldsSkipped(t *testing.T) {
+
+	type user struct {
+		UserID    int64  `db:"user_id"`
+		FirstName string `db:"first_name"`
+		secret    string `db:"secret"`
+	}
+
+	fake, db := newFakeDB(t)
+	fake.setRows("SELECT", []string{"user_id", "first_name", "secret"}, []driver.Value{int64(1), []byte("Alice"), []byte("x")})
+
+	var users []user
+	if err := db.QueryIntoSlice("SELECT user_id, first_name, secret FROM my_users", nil, &users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].FirstName != "Alice" || users[0].secret != "" {
+		t.Errorf("got %+v, want Alice without the unexported field", users)
+	}
+
+	if err := db.InsertTableRowFromStruct("my_users", &user{UserID: 2, FirstName: "Bob", secret: "y"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateTableRowFromStruct("my_users", "user_id", user{UserID: 2, FirstName: "Bob", secret: "y"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range fake.statements() {
+		if strings.Contains(statement, "secret =") || strings.Contains(statement, ", secret)") {
+			t.Errorf("ran %q, writing the unexported field", statement)
+		}
+	}
+}
+
+func TestMaxRowsLimit(t *testing.T) {
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT user_id FROM my_users", "SELECT user_id FROM my_users LIMIT 3 []"},
+		{"WITH u AS (SELECT user_id FROM my_users) SELECT user_id FROM u", "WITH u AS (SELECT user_id FROM my_users) SELECT user_id FROM u LIMIT 3 []"},
+		{"SELECT user_id FROM my_users WHERE note = 'no limit'", "SELECT user_id FROM my_users WHERE note = 'no limit' LIMIT 3 []"},
+		{"SELECT user_id FROM my_users LIMIT 10", "SELECT user_id FROM my_users LIMIT 10 []"},
+		{"SELECT user_id FROM my_users;", "SELECT user_id FROM my_users; []"},
+		{"SELECT user_id FROM my_users FOR UPDATE", "SELECT user_id FROM my_users FOR UPDATE []"},
+		{"CALL active_users()", "CALL active_users() []"},
+	}
+
+	for _, test := range tests {
+		fake, db := newFakeDB(t)
+		fake.setRows("", []string{"user_id"}, []driver.Value{int64(1)})
+
+		var users []scanUser
+		if err := db.WithMaxRows(2).QueryIntoSliceByPosition(test.query, nil, []string{"UserID"}, &users); err != nil {
+			t.Fatal(err)
+		}
+		if got := fake.statements(); len(got) != 1 || got[0] != test.want {
+			t.Errorf("%q ran %q, want %q", test.query, got, test.want)
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+
+	tests := map[string]string{
+		"UserID":     "user_id",
+		"firstName":  "first_name",
+		"HTTPServer": "http_server",
+		"Address2":   "address2",
+		"already_ok": "already_ok",
+		"":           "",
+	}
+
+	for name, want := range tests {
+		if got := SnakeCase(name); got != want {
+			t.Errorf("SnakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package drysql
+
+import (
+	"time"
+)
+
+// SlowQuery describes a query whose round trip took longer than the slow query threshold
+type SlowQuery struct {
+	Fingerprint string
+	Query       string
+	Args        []interface{} // nil unless WithSlowQueryArgs
+	ArgCount    int
+	Duration    time.Duration
+	Err         error
+}
+
+// SlowQueryLoggingInterface receives the queries that exceed the threshold set with WithSlowQueryLog
+type SlowQueryLoggingInterface interface {
+	LogSlowQuery(slowQuery SlowQuery)
+}
+
+// WithSlowQueryLog returns a copy of drysql that reports every query whose round trip exceeds threshold to logger.
+// Arguments are redacted, only their number is reported, unless WithSlowQueryArgs is also set.
+func (drysql DrySql) WithSlowQueryLog(threshold time.Duration, logger SlowQueryLoggingInterface) DrySql {
+	drysql.slowQueryThreshold = threshold
+	drysql.slowQueryLogger = logger
+	return drysql
+}
+
+// WithSlowQueryArgs returns a copy of drysql that reports the arguments of slow queries exactly as they were bound,
+// redact anything sensitive in the logger
+func (drysql DrySql) WithSlowQueryArgs() DrySql {
+	drysql.slowQueryArgs = true
+	return drysql
+}
+
+func (drysql DrySql) logSlowQuery(query string, inputs []interface{}, duration time.Duration, err error) {
+
+	if drysql.slowQueryLogger == nil || duration <= drysql.slowQueryThreshold {
+		return
+	}
+
+	slowQuery := SlowQuery{
+		Fingerprint: QueryFingerprint(query),
+		Query:       query,
+		ArgCount:    len(inputs),
+		Duration:    duration,
+		Err:         err,
+	}
+	if drysql.slowQueryArgs {
+		slowQuery.Args = inputs
+	}
+	drysql.slowQueryLogger.LogSlowQuery(slowQuery)
+}
@@ -0,0 +1,45 @@
+package drysql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+type recordingSlowQueryLogger struct {
+	queries []SlowQuery
+}
+
+func (logger *recordingSlowQueryLogger) LogSlowQuery(slowQuery SlowQuery) {
+	logger.queries = append(logger.queries, slowQuery)
+}
+
+func TestSlowQueryLogRedactsArgs(t *testing.T) {
+
+	fake, appDB := newFakeDB(t)
+	fake.setRows("SELECT", []string{"first_name"}, []driver.Value{[]byte("Ann")})
+
+	for _, withArgs := range []bool{false, true} {
+		logger := &recordingSlowQueryLogger{}
+		db := appDB.WithSlowQueryLog(-1, logger)
+		if withArgs {
+			db = db.WithSlowQueryArgs()
+		}
+
+		var firstName string
+		if err := db.QueryRow("SELECT first_name FROM my_users WHERE email = ? AND password_hash = ?", []interface{}{"ann@example.com", "hash"}, []interface{}{&firstName}); err != nil {
+			t.Fatal(err)
+		}
+		if len(logger.queries) != 1 {
+			t.Fatalf("logged %d slow queries, want 1", len(logger.queries))
+		}
+
+		var wantArgs []interface{}
+		if withArgs {
+			wantArgs = []interface{}{"ann@example.com", "hash"}
+		}
+		if got := logger.queries[0]; got.ArgCount != 2 || !reflect.DeepEqual(got.Args, wantArgs) {
+			t.Errorf("withArgs %v logged %d args %v, want 2 args %v", withArgs, got.ArgCount, got.Args, wantArgs)
+		}
+	}
+}
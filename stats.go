@@ -0,0 +1,62 @@
+package drysql
+
+import (
+	"database/sql"
+	"time"
+)
+
+// QueryStats is the execution metadata of one query, reported to a SqlLogger implementing QueryStatsInterface
+type QueryStats struct {
+	Fingerprint string
+	Query       string
+	Duration    time.Duration // round trip of the prepare and execute, not including reading the rows
+	Err         error
+}
+
+// QueryStatsInterface can optionally be implemented by SqlLogger to receive the QueryStats of every query, e.g. to feed
+// a latency histogram keyed by Fingerprint.  It is called synchronously so it should not block.
+type QueryStatsInterface interface {
+	QueryStats(stats QueryStats)
+}
+
+func logQueryStats(query string, duration time.Duration, err error) {
+	if statsLogger, ok := SqlLogger.(QueryStatsInterface); ok {
+		statsLogger.QueryStats(QueryStats{Fingerprint: QueryFingerprint(query), Query: query, Duration: duration, Err: err})
+	}
+}
+
+// MySQLLastStatementRows returns the rows examined and rows sent of the previous statement run on the connection, read from
+// the MySQL performance schema, to spot queries examining far more rows than they return.  drysql must be bound to a
+// transaction with WithTx so both statements run on the same connection, a *sql.DB may pick different pooled connections.
+// Requires performance_schema with the events_statements_history consumer enabled.
+
+/* 	EXAMPLE USAGE
+
+	tx, err := sqlDB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	db := drysql.WithTx(tx)
+	err = db.QueryIntoSlice("SELECT user_id, first_name FROM my_users WHERE last_name = ?", []interface{}{"Smith"}, &users)
+	examined, sent, err := db.MySQLLastStatementRows()
+*/
+
+func (drysql DrySql) MySQLLastStatementRows() (rowsExamined int64, rowsSent int64, err error) {
+
+	// run unprepared, and skip the prepare and close commands of the previous statement, so the row read is that statement's execution
+	found := false
+	err = drysql.QueryWithoutPrepare(`SELECT ROWS_EXAMINED, ROWS_SENT FROM performance_schema.events_statements_history
+		WHERE THREAD_ID = (SELECT THREAD_ID FROM performance_schema.threads WHERE PROCESSLIST_ID = CONNECTION_ID())
+		AND EVENT_NAME NOT LIKE 'statement/com/%'
+		ORDER BY EVENT_ID DESC LIMIT 1`, func(rows *sql.Rows) error {
+		found = true
+		return rows.Scan(&rowsExamined, &rowsSent)
+	})
+	if err == nil && !found {
+		err = ErrNotFound
+	}
+
+	return rowsExamined, rowsSent, err
+}
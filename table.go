@@ -0,0 +1,56 @@
+package drysql
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Tabler is implemented by structs that name their own table, it overrides any table namer
+type Tabler interface {
+	TableName() string
+}
+
+// WithTableNamer returns a copy of drysql that derives the table name from the struct type name with namer when
+// SelectTableRows, UpdateTableRowFromStruct, BatchUpsertFromStructs or CreateTableSQL are passed an empty table name
+// and the struct does not implement Tabler, e.g. WithTableNamer(drysql.SnakeCase) for singular table names.
+// Without a namer DefaultTableName is used.
+func (drysql DrySql) WithTableNamer(namer func(structName string) string) DrySql {
+	drysql.tableNamer = namer
+	return drysql
+}
+
+// DefaultTableName converts a struct type name to a snake_case plural table name, e.g. User -> users and OrderLine -> order_lines
+func DefaultTableName(structName string) string {
+
+	name := SnakeCase(structName)
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+
+	return name + "s"
+}
+
+// tableName returns tableName when it is not empty, otherwise the name of the table for the struct type of value
+func (drysql DrySql) tableName(tableName string, value interface{}) string {
+
+	if tableName != "" {
+		return tableName
+	}
+
+	t := indirectType(value)
+	if t == nil || t.Kind() != reflect.Struct {
+		return tableName
+	}
+	if tabler, ok := reflect.New(t).Interface().(Tabler); ok {
+		return tabler.TableName()
+	}
+	if drysql.tableNamer != nil {
+		return drysql.tableNamer(t.Name())
+	}
+
+	return DefaultTableName(t.Name())
+}
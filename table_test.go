@@ -0,0 +1,56 @@
+package drysql
+
+import (
+	"testing"
+)
+
+func TestDefaultTableName(t *testing.T) {
+
+	tests := map[string]string{
+		"User":      "users",
+		"OrderLine": "order_lines",
+		"Address":   "addresses",
+		"Box":       "boxes",
+		"Batch":     "batches",
+		"Category":  "categories",
+		"Key":       "keys",
+		"HTTPLog":   "http_logs",
+	}
+
+	for structName, want := range tests {
+		if got := DefaultTableName(structName); got != want {
+			t.Errorf("DefaultTableName(%q) = %q, want %q", structName, got, want)
+		}
+	}
+}
+
+type namedRow struct{}
+
+func (namedRow) TableName() string {
+	return "legacy_rows"
+}
+
+type LineItem struct{}
+
+func TestTableName(t *testing.T) {
+
+	tests := []struct {
+		drysql    DrySql
+		tableName string
+		value     interface{}
+		want      string
+	}{
+		{DrySql{}, "explicit", LineItem{}, "explicit"},
+		{DrySql{}, "", LineItem{}, "line_items"},
+		{DrySql{}, "", &[]*LineItem{}, "line_items"},
+		{DrySql{}, "", namedRow{}, "legacy_rows"},
+		{DrySql{}.WithTableNamer(SnakeCase), "", LineItem{}, "line_item"},
+		{DrySql{}.WithTableNamer(SnakeCase), "", namedRow{}, "legacy_rows"},
+	}
+
+	for _, test := range tests {
+		if got := test.drysql.tableName(test.tableName, test.value); got != test.want {
+			t.Errorf("tableName(%q, %T) = %q, want %q", test.tableName, test.value, got, test.want)
+		}
+	}
+}
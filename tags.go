@@ -0,0 +1,66 @@
+package drysql
+
+import (
+	"reflect"
+	"strings"
+)
+
+// dbTag is a parsed `db:"column_name,option,key=value"` struct tag
+type dbTag struct {
+	name    string
+	options map[string]string
+}
+
+// parseTag reads the db tag of field, a missing tag, a name of "-" or an unexported field, which reflect can't read
+// or set, leaves name empty.
+// Options are split on commas outside of parentheses so `db:"price,type=DECIMAL(10,2)"` keeps its type intact.
+func parseTag(field reflect.StructField) dbTag {
+
+	if field.PkgPath != "" {
+		return dbTag{}
+	}
+
+	var parts []string
+	tag := field.Tag.Get("db")
+	depth, start := 0, 0
+	for i, r := range tag {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, tag[start:])
+
+	parsed := dbTag{name: strings.TrimSpace(parts[0])}
+	if parsed.name == "-" {
+		parsed.name = ""
+	}
+	for _, option := range parts[1:] {
+		if parsed.options == nil {
+			parsed.options = make(map[string]string)
+		}
+		key, value := option, ""
+		if i := strings.Index(option, "="); i >= 0 {
+			key, value = option[:i], option[i+1:]
+		}
+		parsed.options[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+
+	return parsed
+}
+
+func (tag dbTag) has(option string) bool {
+	_, ok := tag.options[option]
+	return ok
+}
+
+func (tag dbTag) get(option string) string {
+	return tag.options[option]
+}
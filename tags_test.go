@@ -0,0 +1,36 @@
+package drysql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+
+	tests := []struct {
+		tag  reflect.StructTag
+		want dbTag
+	}{
+		{`db:"user_id"`, dbTag{name: "user_id"}},
+		{`db:"-"`, dbTag{}},
+		{`json:"id"`, dbTag{}},
+		{`db:"user_id,pk,auto"`, dbTag{name: "user_id", options: map[string]string{"pk": "", "auto": ""}}},
+		{`db:"status,enum=active|inactive"`, dbTag{name: "status", options: map[string]string{"enum": "active|inactive"}}},
+		{`db:"price,type=DECIMAL(10,2),notnull"`, dbTag{name: "price", options: map[string]string{"type": "DECIMAL(10,2)", "notnull": ""}}},
+		{`db:"note, Comment=a=b "`, dbTag{name: "note", options: map[string]string{"comment": "a=b"}}},
+	}
+
+	for _, test := range tests {
+		got := parseTag(reflect.StructField{Name: "Field", Tag: test.tag})
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseTag(%s) = %+v, want %+v", test.tag, got, test.want)
+		}
+	}
+}
+
+func TestParseTagSkipsUnexportedFields(t *testing.T) {
+
+	if got := parseTag(reflect.StructField{Name: "field", PkgPath: "drysql", Tag: `db:"user_id"`}); got.name != "" {
+		t.Errorf("parseTag of an unexported field = %+v, want no column", got)
+	}
+}
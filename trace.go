@@ -0,0 +1,55 @@
+package drysql
+
+import (
+	"context"
+	"strings"
+)
+
+type traceIDKey struct{}
+
+// ContextWithTraceID returns a copy of ctx carrying traceID for WithTraceComment
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace id added to ctx with ContextWithTraceID, or "" when there is none
+func TraceIDFromContext(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
+
+// WithTraceComment returns a copy of drysql that prefixes every query it runs with a /* traceID=... */ comment holding the
+// trace id of ctx, so a query in the database's slow or general log can be matched to the request that ran it.
+// Only letters, digits and -_.: are kept from the trace id.  Queries recorded by the slow query log, WithQueryCapture
+// and WithNPlusOneDetection are left without the comment so their fingerprints don't vary per request.
+
+/* 	EXAMPLE USAGE
+
+	func handler(w http.ResponseWriter, r *http.Request) {
+		ctx := drysql.ContextWithTraceID(r.Context(), r.Header.Get("X-Request-ID"))
+		db := appDB.WithTraceComment(ctx)
+		err := db.QueryIntoSlice("SELECT user_id, first_name FROM my_users", nil, &users)
+	}
+*/
+
+func (drysql DrySql) WithTraceComment(ctx context.Context) DrySql {
+
+	traceID := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:", r) {
+			return r
+		}
+		return -1
+	}, TraceIDFromContext(ctx))
+
+	if traceID == "" {
+		drysql.traceComment = ""
+	} else {
+		drysql.traceComment = "/* traceID=" + traceID + " */ "
+	}
+	return drysql
+}
+
+// traced returns query as sent to the database, with the trace comment when there is one
+func (drysql DrySql) traced(query string) string {
+	return drysql.traceComment + query
+}
@@ -0,0 +1,123 @@
+package drysql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrTreeCycle = errors.New("drysql: rows form a cycle of parent keys")
+
+// QueryIntoTree assembles the flat rows of an adjacency list, e.g. from a recursive CTE, into a tree.
+// dest is a *[]Node or *[]*Node receiving the roots, idColumn and parentColumn are the columns holding a node's key and
+// its parent's key, and childrenField is the name of the Node field holding a []Node or []*Node.
+// Nodes whose parent key is NULL or not in the result are roots, children keep the order they were returned in.
+// ErrTreeCycle is returned when some nodes can't be reached from a root because their parent keys form a cycle.
+
+/* 	EXAMPLE USAGE
+
+	type Category struct {
+		CategoryID int64         `db:"category_id"`
+		ParentID   sql.NullInt64 `db:"parent_id"`
+		Name       string        `db:"name"`
+		Children   []*Category
+	}
+
+	var roots []*Category
+	err = drysql.QueryIntoTree(`WITH RECURSIVE tree AS (...) SELECT category_id, parent_id, name FROM tree ORDER BY depth, name`,
+		nil, "category_id", "parent_id", "Children", &roots)
+*/
+
+func (drysql DrySql) QueryIntoTree(query string, inputs []interface{}, idColumn string, parentColumn string, childrenField string, dest interface{}) error {
+
+	slice, nodeType, isPtr, err := sliceDestination(dest)
+	if err != nil {
+		return err
+	}
+
+	idIndex, ok := taggedField(nodeType, idColumn)
+	if !ok {
+		return fmt.Errorf("drysql: %s has no field tagged %q", nodeType, idColumn)
+	}
+	parentIndex, ok := taggedField(nodeType, parentColumn)
+	if !ok {
+		return fmt.Errorf("drysql: %s has no field tagged %q", nodeType, parentColumn)
+	}
+	children, ok := nodeType.FieldByName(childrenField)
+	if !ok || children.Type.Kind() != reflect.Slice {
+		return fmt.Errorf("drysql: %s has no slice field %s", nodeType, childrenField)
+	}
+	childIsPtr := children.Type.Elem().Kind() == reflect.Ptr
+	if children.Type.Elem() != nodeType && children.Type.Elem() != reflect.PtrTo(nodeType) {
+		return fmt.Errorf("drysql: %s field %s must be a []%s or []*%s", nodeType, childrenField, nodeType, nodeType)
+	}
+
+	nodes := reflect.New(reflect.SliceOf(reflect.PtrTo(nodeType)))
+	if err = drysql.QueryIntoSlice(query, inputs, nodes.Interface()); err != nil {
+		return err
+	}
+	nodes = nodes.Elem()
+
+	byID := make(map[interface{}]int, nodes.Len())
+	for i := 0; i < nodes.Len(); i++ {
+		id := treeKey(nodes.Index(i).Elem().Field(idIndex))
+		if _, seen := byID[id]; seen {
+			return fmt.Errorf("drysql: more than one row has %s %v", idColumn, id)
+		}
+		byID[id] = i
+	}
+
+	var roots []int
+	childIndexes := make(map[int][]int)
+	for i := 0; i < nodes.Len(); i++ {
+		if parent, ok := byID[treeKey(nodes.Index(i).Elem().Field(parentIndex))]; ok {
+			childIndexes[parent] = append(childIndexes[parent], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	// nodes in a cycle have parents but are never reached from a root, so the recursion always terminates
+	linked := 0
+	var link func(i int) reflect.Value
+	link = func(i int) reflect.Value {
+		linked++
+		node := nodes.Index(i)
+		target := node.Elem().FieldByIndex(children.Index)
+		for _, child := range childIndexes[i] {
+			if childNode := link(child); childIsPtr {
+				target.Set(reflect.Append(target, childNode))
+			} else {
+				target.Set(reflect.Append(target, childNode.Elem()))
+			}
+		}
+		return node
+	}
+
+	for _, root := range roots {
+		if node := link(root); isPtr {
+			slice.Set(reflect.Append(slice, node))
+		} else {
+			slice.Set(reflect.Append(slice, node.Elem()))
+		}
+	}
+
+	if linked < nodes.Len() {
+		return ErrTreeCycle
+	}
+
+	return nil
+}
+
+// treeKey converts a key field to its driver value so an int64 id matches a *int64 or sql.NullInt64 parent key
+func treeKey(field reflect.Value) interface{} {
+	value, err := driver.DefaultParameterConverter.ConvertValue(field.Interface())
+	if err != nil {
+		return groupKey(field)
+	}
+	if bytes, ok := value.([]byte); ok {
+		return string(bytes)
+	}
+	return value
+}
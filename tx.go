@@ -0,0 +1,62 @@
+package drysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var ErrTransactionsUnsupported = errors.New("drysql: SqlInterface does not implement BeginTx")
+
+// txBeginner is implemented by *sql.DB and *sql.Conn
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// WithTx returns a copy of drysql that runs its queries in tx, keeping the rest of its configuration
+func (drysql DrySql) WithTx(tx *sql.Tx) DrySql {
+	drysql.sqlImpl = tx
+	return drysql
+}
+
+// ReadSnapshot runs reads, in order, within one read only transaction at the given isolation level so they all see
+// the same snapshot of the database.  Each read is passed a DrySql bound to the transaction, which is committed once
+// every read has succeeded and rolled back as soon as one fails.  Use sql.LevelRepeatableRead or sql.LevelSerializable,
+// on MySQL InnoDB the snapshot is taken by the first read rather than when the transaction begins.
+
+/* 	EXAMPLE USAGE
+
+	var user User
+	var orders []Order
+	err = drysql.ReadSnapshot(ctx, sql.LevelRepeatableRead,
+		func(tx drysql.DrySql) error {
+			return tx.QueryRow("SELECT first_name FROM my_users WHERE user_id = ?", []interface{}{userID}, []interface{}{&user.FirstName})
+		},
+		func(tx drysql.DrySql) error {
+			return tx.SelectTableRows("orders", &orders, drysql.Where("user_id = ?", userID))
+		},
+	)
+*/
+
+func (drysql DrySql) ReadSnapshot(ctx context.Context, isolation sql.IsolationLevel, reads ...func(tx DrySql) error) error {
+
+	beginner, ok := drysql.sqlImpl.(txBeginner)
+	if !ok {
+		return ErrTransactionsUnsupported
+	}
+
+	tx, err := beginner.BeginTx(ctx, &sql.TxOptions{Isolation: isolation, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+
+	txDrySql := drysql.WithTx(tx)
+	for _, read := range reads {
+		if err = read(txDrySql); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
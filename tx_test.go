@@ -0,0 +1,58 @@
+package drysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReadSnapshot(t *testing.T) {
+
+	fake, db := newFakeDB(t)
+	fake.setRows("SELECT", []string{"user_id"}, []driver.Value{int64(1)})
+
+	var escaped DrySql
+	read := func(tx DrySql) error {
+		escaped = tx
+		_, err := tx.QueryRowInt("SELECT user_id FROM my_users", nil)
+		return err
+	}
+	if err := db.ReadSnapshot(context.Background(), sql.LevelRepeatableRead, read, read); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"BEGIN isolation=4 readonly=true", "SELECT user_id FROM my_users []", "SELECT user_id FROM my_users []", "COMMIT"}
+	if got := fake.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+
+	// statements are prepared per call on the transaction, so nothing prepared in it outlives it
+	if _, err := escaped.QueryRowInt("SELECT user_id FROM my_users", nil); err != sql.ErrTxDone {
+		t.Errorf("query on the committed transaction returned %v, want sql.ErrTxDone", err)
+	}
+	if _, err := db.QueryRowInt("SELECT user_id FROM my_users", nil); err != nil {
+		t.Errorf("query outside the transaction returned %v", err)
+	}
+}
+
+func TestReadSnapshotRollsBack(t *testing.T) {
+
+	fake, db := newFakeDB(t)
+	failure := errors.New("read failed")
+
+	err := db.ReadSnapshot(context.Background(), sql.LevelDefault, func(tx DrySql) error { return failure }, func(tx DrySql) error {
+		t.Error("read after the failed read ran")
+		return nil
+	})
+	if err != failure {
+		t.Errorf("ReadSnapshot returned %v, want %v", err, failure)
+	}
+
+	want := []string{"BEGIN isolation=0 readonly=true", "ROLLBACK"}
+	if got := fake.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package drysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// comparedColumn matches the column compared with the placeholder ending the text, including later values of an IN list
+var comparedColumn = regexp.MustCompile("([A-Za-z_][A-Za-z0-9_.`\"]*)\\s*(?:=|<>|!=|<=|>=|<|>|\\s(?i:in)\\s*\\()\\s*(?:\\?\\s*,\\s*)*$")
+
+type columnTypeCache struct {
+	mutex  sync.Mutex
+	tables map[string]map[string]string // table name to lower case column name to DATA_TYPE
+}
+
+// WithTypeMismatchWarnings returns a copy of drysql that logs a warning through SqlWarningInterface when a helper compares a string
+// column with a numeric argument, e.g. drysql.Where("phone = ?", 5551234) against a VARCHAR phone.  MySQL then converts
+// every value of the column to a number, so an index on the column can't be used.  Column types are read from
+// information_schema once per table and cached by the returned DrySql and its copies.
+// Only MySQL is checked, Postgres rejects such comparisons outright.  Intended for development and tests.
+func (drysql DrySql) WithTypeMismatchWarnings() DrySql {
+	drysql.columnTypes = &columnTypeCache{tables: make(map[string]map[string]string)}
+	return drysql
+}
+
+// warnOnTypeMismatch checks the placeholders of conditions and any scope that are compared with a column of tableName
+func (drysql DrySql) warnOnTypeMismatch(tableName string, conditions []Condition) {
+
+	if drysql.columnTypes == nil || drysql.dialect != MySQL {
+		return
+	}
+
+	columnTypes := drysql.tableColumnTypes(tableName)
+	for _, condition := range append(append([]Condition{}, drysql.scope...), conditions...) {
+		clause := condition.Clause
+		arg := 0
+		for i := 0; i < len(clause); i++ {
+			if end := drysql.literalEnd(clause, i); end > i {
+				i = end - 1
+			} else if clause[i] == '?' {
+				if arg < len(condition.Args) {
+					drysql.checkArgType(clause, clause[:i], condition.Args[arg], columnTypes)
+				}
+				arg++
+			}
+		}
+	}
+}
+
+func (drysql DrySql) checkArgType(clause string, prefix string, arg interface{}, columnTypes map[string]string) {
+
+	match := comparedColumn.FindStringSubmatch(prefix)
+	if match == nil {
+		return
+	}
+	column := strings.Trim(match[1], "`\"")
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		column = strings.Trim(column[i+1:], "`\"")
+	}
+
+	switch columnTypes[strings.ToLower(column)] {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set":
+	default:
+		return
+	}
+
+	value, err := driver.DefaultParameterConverter.ConvertValue(arg)
+	if err != nil {
+		return
+	}
+	switch value.(type) {
+	case int64, float64:
+		logSqlWarning(clause, fmt.Sprintf("string column %s is compared with the numeric argument %v, MySQL can't use an index on %s for this comparison, bind a string instead", column, value, column))
+	}
+}
+
+// tableColumnTypes returns the cached DATA_TYPE of each column of tableName, reading them on first use
+func (drysql DrySql) tableColumnTypes(tableName string) map[string]string {
+
+	cache := drysql.columnTypes
+	cache.mutex.Lock()
+	columnTypes, ok := cache.tables[tableName]
+	cache.mutex.Unlock()
+	if ok {
+		return columnTypes
+	}
+
+	columnTypes = make(map[string]string)
+	schema := "DATABASE()"
+	inputs := []interface{}{tableName}
+	if i := strings.LastIndex(tableName, "."); i >= 0 {
+		schema = "?"
+		inputs = []interface{}{tableName[:i], tableName[i+1:]}
+	}
+	query := "SELECT COLUMN_NAME, DATA_TYPE FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = " + schema + " AND TABLE_NAME = ?"
+	err := drysql.PreparedQuery(query, inputs, func(rows *sql.Rows) error {
+		var column, dataType string
+		if err := rows.Scan(&column, &dataType); err != nil {
+			return err
+		}
+		columnTypes[strings.ToLower(column)] = strings.ToLower(dataType)
+		return nil
+	})
+	if err != nil {
+		// leave the table unchecked rather than failing the query being checked
+		return columnTypes
+	}
+
+	cache.mutex.Lock()
+	cache.tables[tableName] = columnTypes
+	cache.mutex.Unlock()
+
+	return columnTypes
+}
@@ -0,0 +1,206 @@
+package drysql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BatchError is returned by the chunked helpers when their context ends part way through, Err is the context's error
+type BatchError struct {
+	ChunksCompleted int
+	Chunks          int
+	Err             error
+}
+
+func (err *BatchError) Error() string {
+	return fmt.Sprintf("drysql: batch stopped after %d of %d chunks: %v", err.ChunksCompleted, err.Chunks, err.Err)
+}
+
+func (err *BatchError) Unwrap() error {
+	return err.Err
+}
+
+// maxParameters is the number of bound parameters a single statement can safely use for the dialect
+func (dialect Dialect) maxParameters() int {
+	switch dialect {
+	case SQLite:
+		return 999
+	default:
+		return 65535
+	}
+}
+
+// WithUpsertIfNewer returns a copy of drysql whose BatchUpsertFromStructs only overwrites an existing row when the
+// incoming versionColumn value, e.g. a version number or updated_at timestamp, is greater than the stored one,
+// so a stale write arriving late is ignored rather than undoing a newer one.  versionColumn must be one of the written columns.
+// MySQL counts a row left unchanged as 0 rows affected, as do Postgres and SQLite.
+func (drysql DrySql) WithUpsertIfNewer(versionColumn string) DrySql {
+	drysql.upsertVersionColumn = versionColumn
+	return drysql
+}
+
+// upsertClause returns the dialect's conflict handling clause, updating every column that is not a conflict column
+func (drysql DrySql) upsertClause(tableName string, columns []string, conflictColumns []string) string {
+
+	// the version column is assigned last as MySQL evaluates the assignments left to right
+	var version string
+	var ordered []string
+	for _, column := range columns {
+		if drysql.upsertVersionColumn != "" && strings.EqualFold(column, drysql.upsertVersionColumn) {
+			version = column
+		} else {
+			ordered = append(ordered, column)
+		}
+	}
+	if version != "" {
+		columns = append(ordered, version)
+	}
+
+	var updates []string
+	for _, column := range columns {
+		isConflictColumn := false
+		for _, conflictColumn := range conflictColumns {
+			if strings.EqualFold(column, conflictColumn) {
+				isConflictColumn = true
+			}
+		}
+		if isConflictColumn {
+			continue
+		}
+
+		column = drysql.quote(column)
+		if drysql.dialect == MySQL && version != "" {
+			newer := "VALUES(" + drysql.quote(version) + ") > " + drysql.quote(version)
+			updates = append(updates, column+" = IF("+newer+", VALUES("+column+"), "+column+")")
+		} else if drysql.dialect == MySQL {
+			updates = append(updates, column+" = VALUES("+column+")")
+		} else {
+			updates = append(updates, column+" = excluded."+column)
+		}
+	}
+
+	if drysql.dialect == MySQL {
+		if len(updates) == 0 {
+			// nothing to update, assign a conflict column to itself so the duplicate is ignored
+			column := drysql.quote(conflictColumns[0])
+			updates = append(updates, column+" = "+column)
+		}
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	}
+
+	conflict := strings.Join(drysql.quoteAll(conflictColumns), ", ")
+	if len(updates) == 0 {
+		return " ON CONFLICT (" + conflict + ") DO NOTHING"
+	}
+	if version != "" {
+		return " ON CONFLICT (" + conflict + ") DO UPDATE SET " + strings.Join(updates, ", ") +
+			" WHERE excluded." + drysql.quote(version) + " > " + drysql.quote(tableName) + "." + drysql.quote(version)
+	}
+	return " ON CONFLICT (" + conflict + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
+
+// BatchUpsertFromStructs inserts every struct in the slice structs into tableName, updating the existing row when
+// one of conflictColumns already exists.  Every db tagged field is written, nil pointers and Null as NULL.
+// Rows are written with multi-row INSERT statements chunked to stay under the dialect's parameter limit,
+// the returned count is the RowsAffected summed across all chunks.  Note MySQL counts an updated row as 2.
+// MySQL matches conflicts against the table's unique keys, conflictColumns are only excluded from the update there.
+// Returns ErrUpsertScoped with a scope added WithScope, an existing row outside the scope would be overwritten.
+
+/* 	EXAMPLE USAGE
+
+	users := []User{{UserID: 1, FirstName: "Ann"}, {UserID: 2, FirstName: "Bob"}}
+	rowsAffected, err := drysql.BatchUpsertFromStructs("my_users", []string{"user_id"}, users)
+*/
+
+func (drysql DrySql) BatchUpsertFromStructs(tableName string, conflictColumns []string, structs interface{}) (int64, error) {
+	return drysql.BatchUpsertFromStructsContext(context.Background(), tableName, conflictColumns, structs)
+}
+
+// BatchUpsertFromStructsContext is BatchUpsertFromStructs with ctx bounding the whole batch rather than each chunk.
+// Chunks are not started once ctx is done, a chunk cut short by ctx is rolled back by the database as statements are atomic.
+// Either way the rows affected by the completed chunks are returned with a *BatchError, errors.Is(err, context.DeadlineExceeded) reports a timeout.
+func (drysql DrySql) BatchUpsertFromStructsContext(ctx context.Context, tableName string, conflictColumns []string, structs interface{}) (int64, error) {
+
+	v := reflect.ValueOf(structs)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	structType := indirectType(structs)
+	if v.Kind() != reflect.Slice || structType == nil || structType.Kind() != reflect.Struct || len(conflictColumns) == 0 {
+		return 0, ErrInvalidDestination
+	}
+	if len(drysql.scope) > 0 {
+		return 0, ErrUpsertScoped
+	}
+	if v.Len() == 0 {
+		return 0, nil
+	}
+	for i := 0; i < v.Len(); i++ {
+		if elem := v.Index(i); elem.Kind() == reflect.Ptr && elem.IsNil() {
+			return 0, fmt.Errorf("drysql: element %d of the batch is nil", i)
+		}
+		if err := validate(v.Index(i).Interface()); err != nil {
+			return 0, err
+		}
+	}
+
+	fields := insertFields(structType)
+	if len(fields) == 0 {
+		return 0, ErrInvalidDestination
+	}
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = field.tag.name
+	}
+
+	tableName = drysql.tableName(tableName, structs)
+	rowPlaceholders := "(" + strings.Repeat("?, ", len(fields)-1) + "?)"
+	upsert := drysql.upsertClause(tableName, columns, conflictColumns)
+	chunkSize := drysql.dialect.maxParameters() / len(fields)
+	if chunkSize == 0 {
+		return 0, fmt.Errorf("drysql: %s has %d fields, more than the %d parameters a statement can bind", structType, len(fields), drysql.dialect.maxParameters())
+	}
+	chunks := (v.Len() + chunkSize - 1) / chunkSize
+
+	var total int64
+	for start := 0; start < v.Len(); start += chunkSize {
+		if err := ctx.Err(); err != nil {
+			return total, &BatchError{ChunksCompleted: start / chunkSize, Chunks: chunks, Err: err}
+		}
+
+		end := start + chunkSize
+		if end > v.Len() {
+			end = v.Len()
+		}
+
+		var inputs []interface{}
+		placeholders := make([]string, 0, end-start)
+		for i := start; i < end; i++ {
+			elem := reflect.Indirect(v.Index(i))
+			values, err := drysql.insertValues(elem, fields)
+			if err != nil {
+				return total, err
+			}
+			inputs = append(inputs, values...)
+			placeholders = append(placeholders, rowPlaceholders)
+		}
+
+		query := "INSERT INTO " + drysql.quote(tableName) + " (" + strings.Join(drysql.quoteAll(columns), ", ") + ") VALUES " + strings.Join(placeholders, ", ") + upsert
+		result, err := drysql.preparedExecContext(ctx, drysql.rebind(query), inputs)
+		if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+			return total, &BatchError{ChunksCompleted: start / chunkSize, Chunks: chunks, Err: ctxErr}
+		} else if err != nil {
+			return total, err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += rowsAffected
+	}
+
+	return total, nil
+}
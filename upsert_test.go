@@ -0,0 +1,82 @@
+package drysql
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUpsertClause(t *testing.T) {
+
+	columns := []string{"user_id", "version", "first_name"}
+	tests := []struct {
+		drysql          DrySql
+		columns         []string
+		conflictColumns []string
+		want            string
+	}{
+		{DrySql{dialect: MySQL}, columns, []string{"user_id"},
+			" ON DUPLICATE KEY UPDATE version = VALUES(version), first_name = VALUES(first_name)"},
+		{DrySql{dialect: MySQL}, []string{"user_id"}, []string{"user_id"},
+			" ON DUPLICATE KEY UPDATE user_id = user_id"},
+		{DrySql{dialect: Postgres}, columns, []string{"user_id"},
+			" ON CONFLICT (user_id) DO UPDATE SET version = excluded.version, first_name = excluded.first_name"},
+		{DrySql{dialect: SQLite}, []string{"user_id"}, []string{"user_id"},
+			" ON CONFLICT (user_id) DO NOTHING"},
+		{DrySql{dialect: MySQL, upsertVersionColumn: "version"}, columns, []string{"user_id"},
+			" ON DUPLICATE KEY UPDATE first_name = IF(VALUES(version) > version, VALUES(first_name), first_name), version = IF(VALUES(version) > version, VALUES(version), version)"},
+		{DrySql{dialect: Postgres, upsertVersionColumn: "version"}, columns, []string{"user_id"},
+			" ON CONFLICT (user_id) DO UPDATE SET first_name = excluded.first_name, version = excluded.version WHERE excluded.version > users.version"},
+		{DrySql{dialect: Postgres, quoteIdentifiers: true}, columns, []string{"user_id"},
+			` ON CONFLICT ("user_id") DO UPDATE SET "version" = excluded."version", "first_name" = excluded."first_name"`},
+	}
+
+	for _, test := range tests {
+		if got := test.drysql.upsertClause("users", test.columns, test.conflictColumns); got != test.want {
+			t.Errorf("upsertClause(%q, %q) = %q, want %q", test.columns, test.conflictColumns, got, test.want)
+		}
+	}
+}
+
+func TestBatchUpsertRejectsInvalidBatches(t *testing.T) {
+
+	type row struct {
+		ID int64 `db:"id"`
+	}
+
+	_, db := newFakeDB(t)
+	if _, err := db.BatchUpsertFromStructs("rows", []string{"id"}, []*row{{ID: 1}, nil}); err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Errorf("batch with a nil element returned %v", err)
+	}
+
+	// more fields than the 999 parameters SQLite can bind in one statement
+	fields := make([]reflect.StructField, 1000)
+	for i := range fields {
+		fields[i] = reflect.StructField{Name: "F" + strconv.Itoa(i), Type: reflect.TypeOf(int64(0)), Tag: reflect.StructTag(`db:"f` + strconv.Itoa(i) + `"`)}
+	}
+	wide := reflect.MakeSlice(reflect.SliceOf(reflect.StructOf(fields)), 1, 1).Interface()
+	if _, err := db.WithDialect(SQLite).BatchUpsertFromStructs("rows", []string{"f0"}, wide); err == nil || !strings.Contains(err.Error(), "parameters") {
+		t.Errorf("batch of a struct wider than a statement returned %v", err)
+	}
+}
+
+func TestUpsertRejectsScope(t *testing.T) {
+
+	type row struct {
+		ID  int64  `db:"id"`
+		Key string `db:"idempotency_key"`
+	}
+
+	fake, appDB := newFakeDB(t)
+	db := appDB.WithScope("tenant_id = ?", 7)
+	if _, err := db.BatchUpsertFromStructs("rows", []string{"id"}, []row{{ID: 1}}); err != ErrUpsertScoped {
+		t.Errorf("scoped BatchUpsertFromStructs returned %v, want ErrUpsertScoped", err)
+	}
+	if _, err := db.InsertFromStructIdempotent("rows", row{ID: 1, Key: "k"}, "idempotency_key"); err != ErrUpsertScoped {
+		t.Errorf("scoped InsertFromStructIdempotent returned %v, want ErrUpsertScoped", err)
+	}
+	if ran := fake.statements(); len(ran) != 0 {
+		t.Errorf("ran %q", ran)
+	}
+}
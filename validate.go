@@ -0,0 +1,31 @@
+package drysql
+
+import (
+	"reflect"
+)
+
+// Validator is implemented by structs that check themselves before being written.  UpdateTableRowFromStruct,
+// UpdateTableRowFromDiff, InsertFromStructIdempotent, BuildInsert and BatchUpsertFromStructs call Validate, with a value
+// or pointer receiver, before generating any SQL and return its error without writing anything.
+type Validator interface {
+	Validate() error
+}
+
+// validate calls the Validate method of value when it has one
+func validate(value interface{}) error {
+
+	if validator, ok := value.(Validator); ok {
+		return validator.Validate()
+	}
+
+	// a value whose Validate has a pointer receiver
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Struct {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		if validator, ok := ptr.Interface().(Validator); ok {
+			return validator.Validate()
+		}
+	}
+
+	return nil
+}